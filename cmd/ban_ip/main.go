@@ -5,6 +5,7 @@ import (
 	"net"
 	"os"
 
+	"github.com/alienth/fastlyctl/fastlyapi"
 	"github.com/alienth/fastlyctl/util"
 	"github.com/alienth/go-fastly"
 	"github.com/urfave/cli"
@@ -20,8 +21,8 @@ func main() {
 	app.Flags = []cli.Flag{
 		cli.StringFlag{
 			Name:   "fastly-key, K",
-			Usage:  "Fastly API Key. Can be read from 'fastly_key' file in CWD.",
-			EnvVar: "FASTLY_KEY",
+			Usage:  "Fastly API Key. If unset, read from FASTLY_KEY, then FASTLY_API_TOKEN, then the 'fastly_key' file in CWD.",
+			EnvVar: util.FastlyKeyEnvVars,
 			Value:  util.GetFastlyKey(),
 		},
 		cli.BoolFlag{
@@ -54,14 +55,16 @@ func main() {
 		services = make([]*fastly.Service, len(serviceNames))
 
 		if len(serviceNames) == 0 {
-			results, _, err := client.Service.List()
+			results, _, err := fastlyapi.New(client).Service.List()
 			if err != nil {
 				return fmt.Errorf("Error fetching service list.")
 			}
-			services = results
+			for _, s := range results {
+				services = append(services, &s.Service)
+			}
 		} else {
 			for i, name := range serviceNames {
-				service, err := util.GetServiceByName(client, name)
+				service, err := util.GetService(client, name)
 				if err != nil {
 					return fmt.Errorf("Error fetching service %s: %s.", name, err)
 				}
@@ -127,7 +130,7 @@ func banAdd(c *cli.Context) error {
 		if err != nil {
 			return cli.NewExitError(fmt.Sprintf("Error finding active version for service %s: %s\n", service.Name, err), -1)
 		}
-		dictionary, _, err := client.Dictionary.Get(service.ID, activeVersion, c.GlobalString("dictionary"))
+		dictionary, _, err := fastlyapi.New(client).Dictionary.Get(service.ID, activeVersion, c.GlobalString("dictionary"))
 		if err != nil {
 			fmt.Printf("Unable to fetch dictionary %s on service %s. Skipping\n", c.GlobalString("dictionary"), service.Name)
 			continue
@@ -154,7 +157,7 @@ func banRemove(c *cli.Context) error {
 		if err != nil {
 			return cli.NewExitError(fmt.Sprintf("Error finding active version for service %s: %s\n", service.Name, err), -1)
 		}
-		dictionary, _, err := client.Dictionary.Get(service.ID, activeVersion, c.GlobalString("dictionary"))
+		dictionary, _, err := fastlyapi.New(client).Dictionary.Get(service.ID, activeVersion, c.GlobalString("dictionary"))
 		if err != nil {
 			fmt.Printf("Unable to fetch dictionary %s on service %s. Skipping\n", c.GlobalString("dictionary"), service.Name)
 			continue
@@ -181,12 +184,12 @@ func banList(c *cli.Context) error {
 		if err != nil {
 			return cli.NewExitError(fmt.Sprintf("Error finding active version for service %s: %s\n", service.Name, err), -1)
 		}
-		dictionary, _, err := client.Dictionary.Get(service.ID, activeVersion, c.GlobalString("dictionary"))
+		dictionary, _, err := fastlyapi.New(client).Dictionary.Get(service.ID, activeVersion, c.GlobalString("dictionary"))
 		if err != nil {
 			fmt.Printf("Unable to fetch dictionary %s on service %s. Skipping\n", c.GlobalString("dictionary"), service.Name)
 			continue
 		}
-		items, _, err := client.DictionaryItem.List(service.ID, dictionary.ID)
+		items, _, err := fastlyapi.New(client).DictionaryItem.List(service.ID, dictionary.ID)
 		if err != nil {
 			return cli.NewExitError(fmt.Sprintf("Error listing items: %s\n", err), -1)
 		}
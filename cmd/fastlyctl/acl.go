@@ -1,10 +1,14 @@
 package main
 
 import (
+	"bufio"
 	"fmt"
+	"os"
 	"strconv"
 	"strings"
 
+	"github.com/alienth/fastlyctl/fastlyapi"
+	"github.com/alienth/fastlyctl/log"
 	"github.com/alienth/fastlyctl/util"
 	"github.com/alienth/go-fastly"
 	"github.com/urfave/cli"
@@ -16,7 +20,7 @@ func aclList(c *cli.Context) error {
 	var err error
 	serviceParam := c.Args().Get(0)
 	var service *fastly.Service
-	if service, _, err = client.Service.Search(serviceParam); err != nil {
+	if service, err = util.GetService(client, serviceParam); err != nil {
 		return cli.NewExitError(err.Error(), -1)
 	}
 	activeVersion, err := util.GetActiveVersion(service)
@@ -26,7 +30,7 @@ func aclList(c *cli.Context) error {
 
 	acls, _, err := client.ACL.List(service.ID, activeVersion)
 	if err != nil {
-		return cli.NewExitError(fmt.Sprintf("Unable to list ACLs for service %s\n", service.Name), -1)
+		return cli.NewExitError(fmt.Sprintf("Unable to list ACLs for service %s: %s", service.Name, err), -1)
 	}
 	fmt.Printf("ACLs for %s:\n\n", service.Name)
 	for _, a := range acls {
@@ -38,7 +42,7 @@ func aclList(c *cli.Context) error {
 func getACL(client *fastly.Client, serviceName, aclName string) (*fastly.ACL, error) {
 	var err error
 	var service *fastly.Service
-	if service, _, err = client.Service.Search(serviceName); err != nil {
+	if service, err = util.GetService(client, serviceName); err != nil {
 		return nil, err
 	}
 	activeVersion, err := util.GetActiveVersion(service)
@@ -119,7 +123,7 @@ func aclRemoveEntry(c *cli.Context) error {
 		return cli.NewExitError(err.Error(), -1)
 	}
 
-	var entry = new(fastly.ACLEntry)
+	var entry *fastly.ACLEntry
 	for _, e := range entries {
 		if e.IP == ip && e.Subnet == subnet {
 			entry = e
@@ -161,3 +165,118 @@ func aclListEntries(c *cli.Context) error {
 
 	return nil
 }
+
+// aclBatchSize is the maximum number of entries Fastly accepts in a single
+// batch update.
+const aclBatchSize = 1000
+
+// readACLFile reads ACL entries from a file containing one IP[/MASK] per line,
+// optionally prefixed with ! to negate the entry and followed by a comment.
+// Blank lines and lines beginning with # are ignored.
+func readACLFile(file string) ([]fastly.ACLEntry, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []fastly.ACLEntry
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		var entry fastly.ACLEntry
+		fields := strings.SplitN(text, " ", 2)
+		if len(fields) == 2 {
+			entry.Comment = strings.TrimSpace(fields[1])
+		}
+		address := fields[0]
+		if strings.HasPrefix(address, "!") {
+			entry.Negated = true
+			address = address[1:]
+		}
+		if entry.IP, entry.Subnet, err = ipMaskSplit(address); err != nil {
+			return nil, fmt.Errorf("%s:%d: %s", file, line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+func aclEntryKey(ip string, subnet uint8) string {
+	return fmt.Sprintf("%s/%d", ip, subnet)
+}
+
+func aclBulkEntries(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+
+	serviceParam := c.Args().Get(0)
+	aclParam := c.Args().Get(1)
+	fileParam := c.Args().Get(2)
+
+	newEntries, err := readACLFile(fileParam)
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error reading %s: %s", fileParam, err), -1)
+	}
+
+	acl, err := getACL(client, serviceParam, aclParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	existingEntries, _, err := client.ACLEntry.List(acl.ServiceID, acl.ID)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	existing := make(map[string]*fastly.ACLEntry)
+	for _, e := range existingEntries {
+		existing[aclEntryKey(e.IP, e.Subnet)] = e
+	}
+
+	var updates []fastlyapi.ACLEntryUpdate
+	var created, updated, deleted int
+	seen := make(map[string]bool)
+	for _, e := range newEntries {
+		key := aclEntryKey(e.IP, e.Subnet)
+		seen[key] = true
+		update := fastlyapi.ACLEntryUpdate{IP: e.IP, Comment: e.Comment, Negated: e.Negated}
+		if e.Subnet != 0 {
+			update.Subnet = strconv.Itoa(int(e.Subnet))
+		}
+		if old, ok := existing[key]; ok {
+			if old.Comment == e.Comment && old.Negated == e.Negated {
+				continue
+			}
+			update.Operation = fastly.BatchOperationUpdate
+			update.ID = old.ID
+			updated++
+		} else {
+			update.Operation = fastly.BatchOperationCreate
+			created++
+		}
+		updates = append(updates, update)
+	}
+	if c.Bool("delete-missing") {
+		for key, e := range existing {
+			if !seen[key] {
+				updates = append(updates, fastlyapi.ACLEntryUpdate{Operation: fastly.BatchOperationDelete, ID: e.ID})
+				deleted++
+			}
+		}
+	}
+
+	for i := 0; i < len(updates); i += aclBatchSize {
+		end := i + aclBatchSize
+		if end > len(updates) {
+			end = len(updates)
+		}
+		if _, err = fastlyapi.New(client).ACLEntry.BatchUpdate(acl.ServiceID, acl.ID, updates[i:end]); err != nil {
+			return cli.NewExitError(fmt.Sprintf("Error updating ACL entries: %s", err), -1)
+		}
+	}
+	log.Info(fmt.Sprintf("ACL %s: %d entries created, %d updated, %d deleted\n", aclParam, created, updated, deleted))
+
+	return nil
+}
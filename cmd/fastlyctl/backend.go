@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/alienth/fastlyctl/format"
+	"github.com/alienth/fastlyctl/log"
+	"github.com/alienth/fastlyctl/util"
+	"github.com/alienth/go-fastly"
+	"github.com/urfave/cli"
+)
+
+func backendList(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+
+	serviceParam := c.Args().Get(0)
+	service, err := util.GetService(client, serviceParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	activeVersion, err := util.GetActiveVersion(service)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	backends, _, err := client.Backend.List(service.ID, activeVersion)
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Unable to list backends for service %s: %s", service.Name, err), -1)
+	}
+	if outputFormat(c) != format.Table {
+		output := make([]backendOutput, 0, len(backends))
+		for _, b := range backends {
+			output = append(output, newBackendOutput(b))
+		}
+		return printOutput(c, output)
+	}
+
+	fmt.Printf("Backends for %s:\n\n", service.Name)
+	fmt.Printf("%-30s %-40s %5s  %-3s  %s\n", "Name", "Address", "Port", "TLS", "Shield")
+	for _, b := range backends {
+		shield := b.Shield
+		if shield == "" {
+			shield = "-"
+		}
+		fmt.Printf("%-30s %-40s %5d  %-3s  %s\n", b.Name, b.Address, b.Port, util.YesNo(b.UseSSL), shield)
+	}
+	return nil
+}
+
+// applyBackendFlags sets fields on b for each backend flag which was given.
+func applyBackendFlags(c *cli.Context, b *fastly.Backend) {
+	if c.IsSet("address") {
+		// Address is mirrored into these fields by the API. Clear them
+		// so that they are populated from the new address.
+		b.Address = c.String("address")
+		b.Hostname = ""
+		b.IPV4 = ""
+		b.IPV6 = ""
+	}
+	if c.IsSet("port") {
+		b.Port = uint(c.Int("port"))
+	}
+	if c.IsSet("use-ssl") {
+		b.UseSSL = c.Bool("use-ssl")
+	}
+	if c.IsSet("ssl-cert-hostname") {
+		b.SSLCertHostname = c.String("ssl-cert-hostname")
+	}
+	if c.IsSet("connect-timeout") {
+		b.ConnectTimeout = uint(c.Int("connect-timeout"))
+	}
+}
+
+func backendAdd(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+
+	serviceParam := c.Args().Get(0)
+	nameParam := c.Args().Get(1)
+	addressParam := c.Args().Get(2)
+
+	service, err := util.GetService(client, serviceParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	backend := new(fastly.Backend)
+	backend.Name = nameParam
+	backend.Address = addressParam
+	applyBackendFlags(c, backend)
+
+	version, err := draftVersion(c, client, service)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	if _, _, err = client.Backend.Create(service.ID, version, backend); err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error creating backend: %s", err), -1)
+	}
+	log.Info(fmt.Sprintf("Created backend %s\n", nameParam))
+
+	if err = finishDraft(c, client, service, version); err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	return nil
+}
+
+func backendUpdate(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+
+	serviceParam := c.Args().Get(0)
+	nameParam := c.Args().Get(1)
+
+	service, err := util.GetService(client, serviceParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	version, err := draftVersion(c, client, service)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	backend, _, err := client.Backend.Get(service.ID, version, nameParam)
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error fetching backend %s: %s", nameParam, err), -1)
+	}
+	// Zero out read-only fields
+	backend.ServiceID = ""
+	backend.Version = 0
+	applyBackendFlags(c, backend)
+
+	if _, _, err = client.Backend.Update(service.ID, version, nameParam, backend); err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error updating backend: %s", err), -1)
+	}
+	log.Info(fmt.Sprintf("Updated backend %s\n", nameParam))
+
+	if err = finishDraft(c, client, service, version); err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	return nil
+}
+
+func backendRemove(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+
+	serviceParam := c.Args().Get(0)
+	nameParam := c.Args().Get(1)
+
+	service, err := util.GetService(client, serviceParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	version, err := draftVersion(c, client, service)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	if _, err = client.Backend.Delete(service.ID, version, nameParam); err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error removing backend: %s", err), -1)
+	}
+	log.Info(fmt.Sprintf("Removed backend %s\n", nameParam))
+
+	if err = finishDraft(c, client, service, version); err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	return nil
+}
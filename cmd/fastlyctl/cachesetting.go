@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/alienth/fastlyctl/format"
+	"github.com/alienth/fastlyctl/log"
+	"github.com/alienth/fastlyctl/util"
+	"github.com/alienth/go-fastly"
+	"github.com/urfave/cli"
+)
+
+func cacheSettingList(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+
+	serviceParam := c.Args().Get(0)
+	service, err := util.GetService(client, serviceParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	activeVersion, err := util.GetActiveVersion(service)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	settings, _, err := client.CacheSetting.List(service.ID, activeVersion)
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Unable to list cache settings for service %s: %s", service.Name, err), -1)
+	}
+	if outputFormat(c) != format.Table {
+		output := make([]cacheSettingOutput, 0, len(settings))
+		for _, s := range settings {
+			output = append(output, newCacheSettingOutput(s))
+		}
+		return printOutput(c, output)
+	}
+
+	fmt.Printf("Cache settings for %s:\n\n", service.Name)
+	fmt.Printf("%-30s %-8s %8s %10s  %s\n", "Name", "Action", "TTL", "Stale TTL", "Cache Condition")
+	for _, s := range settings {
+		o := newCacheSettingOutput(s)
+		fmt.Printf("%-30s %-8s %8d %10d  %s\n", o.Name, dashIfEmpty(o.Action), o.TTL, o.StaleTTL, dashIfEmpty(o.CacheCondition))
+	}
+	return nil
+}
+
+// checkCacheSetting is used as the Before hook for cache-settings add and
+// update.
+func checkCacheSetting(c *cli.Context) error {
+	if c.NArg() < 2 {
+		return cli.NewExitError("Please specify cache setting name.", -1)
+	}
+	if c.IsSet("action") {
+		// Unknown values are left unset by UnmarshalText.
+		var action fastly.CacheSettingAction
+		action.UnmarshalText([]byte(c.String("action")))
+		if action == 0 {
+			return cli.NewExitError(fmt.Sprintf("Invalid cache setting action %q. Must be one of: pass, cache, restart", c.String("action")), -1)
+		}
+	}
+	return checkActivate(c)
+}
+
+// applyCacheSettingFlags sets fields on s for each cache setting flag which
+// was given.
+func applyCacheSettingFlags(c *cli.Context, s *fastly.CacheSetting) {
+	if c.IsSet("action") {
+		s.Action.UnmarshalText([]byte(c.String("action")))
+	}
+	if c.IsSet("ttl") {
+		s.TTL = uint(c.Int("ttl"))
+	}
+	if c.IsSet("stale-ttl") {
+		s.StaleTTL = uint(c.Int("stale-ttl"))
+	}
+	if c.IsSet("cache-condition") {
+		s.CacheCondition = c.String("cache-condition")
+	}
+}
+
+func cacheSettingAdd(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+
+	serviceParam := c.Args().Get(0)
+	nameParam := c.Args().Get(1)
+
+	service, err := util.GetService(client, serviceParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	setting := new(fastly.CacheSetting)
+	setting.Name = nameParam
+	applyCacheSettingFlags(c, setting)
+
+	version, err := draftVersion(c, client, service)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	if _, _, err = client.CacheSetting.Create(service.ID, version, setting); err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error creating cache setting: %s", err), -1)
+	}
+	log.Info(fmt.Sprintf("Created cache setting %s\n", nameParam))
+
+	if err = finishDraft(c, client, service, version); err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	return nil
+}
+
+func cacheSettingUpdate(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+
+	serviceParam := c.Args().Get(0)
+	nameParam := c.Args().Get(1)
+
+	service, err := util.GetService(client, serviceParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	version, err := draftVersion(c, client, service)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	setting, _, err := client.CacheSetting.Get(service.ID, version, nameParam)
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error fetching cache setting %s: %s", nameParam, err), -1)
+	}
+	// Zero out read-only fields
+	setting.ServiceID = ""
+	setting.Version = 0
+	applyCacheSettingFlags(c, setting)
+
+	if _, _, err = client.CacheSetting.Update(service.ID, version, nameParam, setting); err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error updating cache setting: %s", err), -1)
+	}
+	log.Info(fmt.Sprintf("Updated cache setting %s\n", nameParam))
+
+	if err = finishDraft(c, client, service, version); err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	return nil
+}
+
+func cacheSettingRemove(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+
+	serviceParam := c.Args().Get(0)
+	nameParam := c.Args().Get(1)
+
+	service, err := util.GetService(client, serviceParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	version, err := draftVersion(c, client, service)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	if _, err = client.CacheSetting.Delete(service.ID, version, nameParam); err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error removing cache setting: %s", err), -1)
+	}
+	log.Info(fmt.Sprintf("Removed cache setting %s\n", nameParam))
+
+	if err = finishDraft(c, client, service, version); err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	return nil
+}
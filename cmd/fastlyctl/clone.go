@@ -0,0 +1,223 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/alienth/fastlyctl/fastlyapi"
+	"github.com/alienth/fastlyctl/util"
+	"github.com/alienth/go-fastly"
+	"github.com/urfave/cli"
+)
+
+func serviceClone(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+	name := c.Args().Get(1)
+
+	src, err := util.GetService(client, c.Args().Get(0))
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	if _, err := util.GetServiceByName(client, name); err == nil {
+		return cli.NewExitError(fmt.Sprintf("Service %s already exists.", name), -1)
+	} else if !util.IsServiceNotFound(err) {
+		return cli.NewExitError(fmt.Sprintf("Unable to check whether service %s exists: %s", name, err), -1)
+	}
+	srcVersion, err := util.GetActiveVersion(src)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	config, err := exportService(client, src, srcVersion, "")
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error reading service %s: %s", src.Name, err), -1)
+	}
+	// Domains can only belong to one service, so they are only set if given
+	// explicitly.
+	if len(config.Domains) > 0 && !c.IsSet("domain") {
+		fmt.Printf("Warning: not copying %d domains from %s. Use --domain to add domains to the new service.\n", len(config.Domains), src.Name)
+	}
+	config.Domains = nil
+	for _, domain := range c.StringSlice("domain") {
+		config.Domains = append(config.Domains, fastly.Domain{Name: domain})
+	}
+
+	if util.DryRun() {
+		fmt.Printf("Dry run: would create service %s and copy %d backends, %d domains, %d dictionaries and %d VCLs from version %d of %s.\n", name, len(config.Backends), len(config.Domains), len(config.Dictionaries), len(config.VCLs), srcVersion, src.Name)
+		return nil
+	}
+
+	if !c.GlobalBool("assume-yes") {
+		proceed, err := util.Prompt(fmt.Sprintf("Create service %s from version %d of %s?", name, srcVersion, src.Name))
+		if err != nil {
+			return cli.NewExitError(err.Error(), -1)
+		}
+		if !proceed {
+			return nil
+		}
+	}
+
+	dest, _, err := client.Service.Create(&fastly.Service{Name: name, Comment: src.Comment})
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error creating service: %s", err), -1)
+	}
+	fmt.Printf("Created service %s with ID %s\n", dest.Name, dest.ID)
+
+	versions, _, err := client.Version.List(dest.ID)
+	if err != nil || len(versions) == 0 {
+		return cli.NewExitError(fmt.Sprintf("Unable to find the initial version of service %s: %v", dest.Name, err), -1)
+	}
+	version := versions[len(versions)-1].Number
+
+	if err := copyServiceConfig(client, src, dest, version, config); err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error copying config to service %s: %s", dest.Name, err), -1)
+	}
+	fmt.Printf("Copied config from version %d of %s onto draft version %d of %s. Validate and activate version %d to apply.\n", srcVersion, src.Name, version, dest.Name, version)
+	return nil
+}
+
+// copyServiceConfig creates the objects in config on a version of dest. The
+// service and version each object was exported from are cleared first, as
+// they are read-only.
+// Dictionary items and ACL entries are copied from src. Objects are created in
+// the same order as push, so that anything referenced by another object
+// exists first.
+func copyServiceConfig(client *fastly.Client, src, dest *fastly.Service, version uint, config SiteConfig) error {
+	api := fastlyapi.New(client)
+	for _, d := range config.Dictionaries {
+		newDictionary, _, err := api.Dictionary.Create(dest.ID, version, &fastlyapi.Dictionary{Name: d.Name, WriteOnly: d.WriteOnly})
+		if err != nil {
+			return fmt.Errorf("Error creating dictionary %s: %s", d.Name, err)
+		}
+		// The values of a write-only dictionary cannot be read, so there
+		// is nothing to copy.
+		if d.WriteOnly {
+			fmt.Fprintf(os.Stderr, "Dictionary %s is write-only, so its items were not copied. Add them to service %s with dictionary item-add or item-bulk.\n", d.Name, dest.Name)
+			continue
+		}
+		items, _, err := api.DictionaryItem.List(src.ID, d.ID)
+		if err != nil {
+			return fmt.Errorf("Error listing items in dictionary %s: %s", d.Name, err)
+		}
+		var updates []fastly.DictionaryItemUpdate
+		for _, item := range items {
+			updates = append(updates, fastly.DictionaryItemUpdate{Operation: fastly.BatchOperationCreate, Key: item.Key, Value: item.Value})
+		}
+		for len(updates) > 0 {
+			batch := updates
+			if len(batch) > dictionaryBatchSize {
+				batch = batch[:dictionaryBatchSize]
+			}
+			if _, err := api.DictionaryItem.BatchUpdate(dest.ID, newDictionary.ID, batch); err != nil {
+				return fmt.Errorf("Error copying items in dictionary %s: %s", d.Name, err)
+			}
+			updates = updates[len(batch):]
+		}
+	}
+	for _, a := range config.ACLs {
+		newACL, _, err := client.ACL.Create(dest.ID, version, &fastly.ACL{Name: a.Name})
+		if err != nil {
+			return fmt.Errorf("Error creating ACL %s: %s", a.Name, err)
+		}
+		entries, _, err := client.ACLEntry.List(src.ID, a.ID)
+		if err != nil {
+			return fmt.Errorf("Error listing entries in ACL %s: %s", a.Name, err)
+		}
+		for _, entry := range entries {
+			newEntry := fastly.ACLEntry{IP: entry.IP, Subnet: entry.Subnet, Comment: entry.Comment, Negated: entry.Negated}
+			if _, _, err := client.ACLEntry.Create(dest.ID, newACL.ID, &newEntry); err != nil {
+				return fmt.Errorf("Error copying entry %s in ACL %s: %s", entry.IP, a.Name, err)
+			}
+		}
+	}
+	for i := range config.Conditions {
+		config.Conditions[i].ServiceID = ""
+		config.Conditions[i].Version = 0
+		if _, _, err := client.Condition.Create(dest.ID, version, &config.Conditions[i]); err != nil {
+			return fmt.Errorf("Error creating condition %s: %s", config.Conditions[i].Name, err)
+		}
+	}
+	for i := range config.HealthChecks {
+		config.HealthChecks[i].ServiceID = ""
+		config.HealthChecks[i].Version = 0
+		if _, _, err := client.HealthCheck.Create(dest.ID, version, &config.HealthChecks[i]); err != nil {
+			return fmt.Errorf("Error creating health check %s: %s", config.HealthChecks[i].Name, err)
+		}
+	}
+	for i := range config.CacheSettings {
+		config.CacheSettings[i].ServiceID = ""
+		config.CacheSettings[i].Version = 0
+		if _, _, err := client.CacheSetting.Create(dest.ID, version, &config.CacheSettings[i]); err != nil {
+			return fmt.Errorf("Error creating cache setting %s: %s", config.CacheSettings[i].Name, err)
+		}
+	}
+	for i := range config.ResponseObject {
+		config.ResponseObject[i].ServiceID = ""
+		config.ResponseObject[i].Version = 0
+		if _, _, err := client.ResponseObject.Create(dest.ID, version, &config.ResponseObject[i]); err != nil {
+			return fmt.Errorf("Error creating response object %s: %s", config.ResponseObject[i].Name, err)
+		}
+	}
+	for i := range config.RequestSettings {
+		config.RequestSettings[i].ServiceID = ""
+		config.RequestSettings[i].Version = 0
+		if _, _, err := client.RequestSetting.Create(dest.ID, version, &config.RequestSettings[i]); err != nil {
+			return fmt.Errorf("Error creating request setting %s: %s", config.RequestSettings[i].Name, err)
+		}
+	}
+	for i := range config.Backends {
+		config.Backends[i].ServiceID = ""
+		config.Backends[i].Version = 0
+		if _, _, err := client.Backend.Create(dest.ID, version, &config.Backends[i]); err != nil {
+			return fmt.Errorf("Error creating backend %s: %s", config.Backends[i].Name, err)
+		}
+	}
+	for i := range config.Headers {
+		config.Headers[i].ServiceID = ""
+		config.Headers[i].Version = 0
+		if _, _, err := client.Header.Create(dest.ID, version, &config.Headers[i]); err != nil {
+			return fmt.Errorf("Error creating header %s: %s", config.Headers[i].Name, err)
+		}
+	}
+	for i := range config.Syslogs {
+		config.Syslogs[i].ServiceID = ""
+		config.Syslogs[i].Version = 0
+		if _, _, err := client.Syslog.Create(dest.ID, version, &config.Syslogs[i]); err != nil {
+			return fmt.Errorf("Error creating syslog %s: %s", config.Syslogs[i].Name, err)
+		}
+	}
+	for i := range config.S3s {
+		config.S3s[i].ServiceID = ""
+		config.S3s[i].Version = 0
+		if _, _, err := client.S3.Create(dest.ID, version, &config.S3s[i]); err != nil {
+			return fmt.Errorf("Error creating s3 %s: %s", config.S3s[i].Name, err)
+		}
+	}
+	for i := range config.Domains {
+		config.Domains[i].ServiceID = ""
+		config.Domains[i].Version = 0
+		if _, _, err := client.Domain.Create(dest.ID, version, &config.Domains[i]); err != nil {
+			return fmt.Errorf("Error creating domain %s: %s", config.Domains[i].Name, err)
+		}
+	}
+	settings := config.Settings
+	settings.ServiceID = ""
+	settings.Version = 0
+	if _, _, err := client.Settings.Update(dest.ID, version, &settings); err != nil {
+		return fmt.Errorf("Error updating settings: %s", err)
+	}
+	for i := range config.Gzips {
+		config.Gzips[i].ServiceID = ""
+		config.Gzips[i].Version = 0
+		if _, _, err := client.Gzip.Create(dest.ID, version, &config.Gzips[i]); err != nil {
+			return fmt.Errorf("Error creating gzip %s: %s", config.Gzips[i].Name, err)
+		}
+	}
+	for _, vcl := range config.VCLs {
+		newVCL := fastly.VCL{Name: vcl.Name, Content: vcl.Content, Main: vcl.Main}
+		if _, _, err := client.VCL.Create(dest.ID, version, &newVCL); err != nil {
+			return fmt.Errorf("Error creating VCL %s: %s", vcl.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/alienth/fastlyctl/format"
+	"github.com/alienth/fastlyctl/log"
+	"github.com/alienth/fastlyctl/util"
+	"github.com/alienth/go-fastly"
+	"github.com/urfave/cli"
+)
+
+func conditionList(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+
+	serviceParam := c.Args().Get(0)
+	service, err := util.GetService(client, serviceParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	activeVersion, err := util.GetActiveVersion(service)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	conditions, _, err := client.Condition.List(service.ID, activeVersion)
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Unable to list conditions for service %s: %s", service.Name, err), -1)
+	}
+	if outputFormat(c) != format.Table {
+		output := make([]conditionOutput, 0, len(conditions))
+		for _, cond := range conditions {
+			output = append(output, newConditionOutput(cond))
+		}
+		return printOutput(c, output)
+	}
+
+	fmt.Printf("Conditions for %s:\n\n", service.Name)
+	fmt.Printf("%-30s %-8s %8s  %s\n", "Name", "Type", "Priority", "Statement")
+	for _, cond := range conditions {
+		o := newConditionOutput(cond)
+		fmt.Printf("%-30s %-8s %8d  %s\n", o.Name, o.Type, o.Priority, o.Statement)
+	}
+	return nil
+}
+
+// checkConditionAdd is used as the Before hook for condition add.
+func checkConditionAdd(c *cli.Context) error {
+	if c.NArg() < 2 {
+		return cli.NewExitError("Please specify condition name.", -1)
+	}
+	if c.String("statement") == "" {
+		return cli.NewExitError("Please specify --statement.", -1)
+	}
+	// Unknown values are left unset by UnmarshalText.
+	var conditionType fastly.ConditionType
+	conditionType.UnmarshalText([]byte(c.String("type")))
+	if conditionType == 0 {
+		return cli.NewExitError(fmt.Sprintf("Invalid condition type %q. Must be one of: request, response, cache", c.String("type")), -1)
+	}
+	return checkActivate(c)
+}
+
+func conditionAdd(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+
+	serviceParam := c.Args().Get(0)
+	nameParam := c.Args().Get(1)
+
+	service, err := util.GetService(client, serviceParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	condition := new(fastly.Condition)
+	condition.Name = nameParam
+	condition.Type.UnmarshalText([]byte(c.String("type")))
+	condition.Statement = c.String("statement")
+	condition.Priority = uint(c.Int("priority"))
+	condition.Comment = c.String("comment")
+
+	version, err := draftVersion(c, client, service)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	if _, _, err = client.Condition.Create(service.ID, version, condition); err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error creating condition: %s", err), -1)
+	}
+	// Statements are not checked when the condition is created, so the
+	// draft is validated to catch syntax errors now rather than at
+	// activation. finishDraft validates the draft itself with --activate.
+	if !c.Bool("activate") {
+		warnings, err := util.ValidateVersion(client, service, version)
+		if err != nil {
+			return cli.NewExitError(fmt.Sprintf("Created condition %s, but %s", nameParam, err), -1)
+		}
+		if len(warnings) > 0 {
+			util.PrintValidationWarnings(service, version, warnings)
+		}
+	}
+	log.Info(fmt.Sprintf("Created condition %s\n", nameParam))
+
+	if err = finishDraft(c, client, service, version); err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	return nil
+}
+
+func conditionRemove(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+
+	serviceParam := c.Args().Get(0)
+	nameParam := c.Args().Get(1)
+
+	service, err := util.GetService(client, serviceParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	version, err := draftVersion(c, client, service)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	if _, err = client.Condition.Delete(service.ID, version, nameParam); err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error removing condition: %s", err), -1)
+	}
+	log.Info(fmt.Sprintf("Removed condition %s\n", nameParam))
+
+	if err = finishDraft(c, client, service, version); err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	return nil
+}
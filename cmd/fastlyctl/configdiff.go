@@ -0,0 +1,277 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/alienth/fastlyctl/fastlyapi"
+	"github.com/alienth/fastlyctl/util"
+	"github.com/alienth/go-fastly"
+	"github.com/urfave/cli"
+)
+
+// ignoredFields are not compared, as they are set by the API.
+var ignoredFields = []string{"ServiceID", "Version", "ID"}
+
+// secretFields have their values hidden when printing changes.
+var secretFields = []string{"AccessKey", "SecretKey"}
+
+// objectChanges summarises the differences between the live and desired
+// objects of a single type.
+type objectChanges struct {
+	Type    string
+	Added   []string
+	Removed []string
+	Changed []objectChange
+}
+
+type objectChange struct {
+	Name   string
+	Fields []string
+}
+
+func (o objectChanges) empty() bool {
+	return len(o.Added) == 0 && len(o.Removed) == 0 && len(o.Changed) == 0
+}
+
+func configDiff(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+	configFile, err := util.GetConfigFile(c)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	if err := readConfig(configFile); err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error reading config file: %s", err), -1)
+	}
+	if err := checkUnknownKeys(c.GlobalBool("lax")); err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error reading config file: %s", err), -1)
+	}
+
+	s, err := util.GetService(client, c.Args().Get(0))
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	config, ok := siteConfigs[s.Name]
+	if !ok {
+		return cli.NewExitError(fmt.Sprintf("Service %s is not defined in configuration.", s.Name), -1)
+	}
+	version, err := util.GetActiveVersion(s)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	changes, err := compareService(client, s, version, config)
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error comparing service %s: %s", s.Name, err), -1)
+	}
+
+	printChanges(s, version, changes)
+	return nil
+}
+
+// printChanges prints the changes found by compareService.
+func printChanges(s *fastly.Service, version uint, changes []objectChanges) {
+	var differences int
+	for _, o := range changes {
+		if o.empty() {
+			continue
+		}
+		differences++
+		fmt.Printf("%s:\n", o.Type)
+		for _, name := range o.Added {
+			fmt.Printf("  + %s\n", name)
+		}
+		for _, name := range o.Removed {
+			fmt.Printf("  - %s\n", name)
+		}
+		for _, change := range o.Changed {
+			fmt.Printf("  ~ %s\n", change.Name)
+			for _, field := range change.Fields {
+				fmt.Printf("      %s\n", field)
+			}
+		}
+	}
+	if differences == 0 {
+		fmt.Printf("Config for %s matches active version %d.\n", s.Name, version)
+	}
+}
+
+// compareService compares each type of object in config against those in the
+// given version of a service.
+func compareService(client *fastly.Client, s *fastly.Service, version uint, config SiteConfig) ([]objectChanges, error) {
+	domains := make([]fastly.Domain, len(config.Domains))
+	copy(domains, config.Domains)
+	prepareDomains(s, domains)
+	backends := make([]fastly.Backend, len(config.Backends))
+	copy(backends, config.Backends)
+	if err := prepareBackends(s, backends); err != nil {
+		return nil, err
+	}
+	healthChecks := make([]fastly.HealthCheck, len(config.HealthChecks))
+	copy(healthChecks, config.HealthChecks)
+	prepareHealthChecks(healthChecks)
+	syslogs := make([]fastly.Syslog, len(config.Syslogs))
+	copy(syslogs, config.Syslogs)
+	prepareSyslogs(s, syslogs)
+	s3s := make([]fastly.S3, len(config.S3s))
+	copy(s3s, config.S3s)
+	prepareS3s(s, s3s)
+	vcls, err := loadVCLs(config.VCLs)
+	if err != nil {
+		return nil, err
+	}
+
+	comparisons := []struct {
+		name    string
+		desired interface{}
+		list    func() (interface{}, error)
+	}{
+		{"Dictionaries", config.Dictionaries, func() (interface{}, error) {
+			l, _, err := fastlyapi.New(client).Dictionary.List(s.ID, version)
+			return l, err
+		}},
+		{"ACLs", config.ACLs, func() (interface{}, error) {
+			l, _, err := client.ACL.List(s.ID, version)
+			return l, err
+		}},
+		{"Conditions", config.Conditions, func() (interface{}, error) {
+			l, _, err := client.Condition.List(s.ID, version)
+			return l, err
+		}},
+		{"HealthChecks", healthChecks, func() (interface{}, error) {
+			l, _, err := client.HealthCheck.List(s.ID, version)
+			return l, err
+		}},
+		{"CacheSettings", config.CacheSettings, func() (interface{}, error) {
+			l, _, err := client.CacheSetting.List(s.ID, version)
+			return l, err
+		}},
+		{"ResponseObjects", config.ResponseObject, func() (interface{}, error) {
+			l, _, err := client.ResponseObject.List(s.ID, version)
+			return l, err
+		}},
+		{"RequestSettings", config.RequestSettings, func() (interface{}, error) {
+			l, _, err := client.RequestSetting.List(s.ID, version)
+			return l, err
+		}},
+		{"Backends", backends, func() (interface{}, error) {
+			l, _, err := client.Backend.List(s.ID, version)
+			return l, err
+		}},
+		{"Headers", config.Headers, func() (interface{}, error) {
+			l, _, err := client.Header.List(s.ID, version)
+			return l, err
+		}},
+		{"Syslogs", syslogs, func() (interface{}, error) {
+			l, _, err := client.Syslog.List(s.ID, version)
+			return l, err
+		}},
+		{"S3s", s3s, func() (interface{}, error) {
+			l, _, err := client.S3.List(s.ID, version)
+			return l, err
+		}},
+		{"Domains", domains, func() (interface{}, error) {
+			l, _, err := client.Domain.List(s.ID, version)
+			return l, err
+		}},
+		{"Gzips", config.Gzips, func() (interface{}, error) {
+			l, _, err := client.Gzip.List(s.ID, version)
+			return l, err
+		}},
+		{"VCLs", vcls, func() (interface{}, error) {
+			l, _, err := client.VCL.List(s.ID, version)
+			return l, err
+		}},
+	}
+
+	var changes []objectChanges
+	for _, comparison := range comparisons {
+		live, err := comparison.list()
+		if err != nil {
+			return nil, fmt.Errorf("Error listing %s: %s", comparison.name, err)
+		}
+		changes = append(changes, compareObjects(comparison.name, live, comparison.desired))
+	}
+
+	settings, _, err := client.Settings.Get(s.ID, version)
+	if err != nil {
+		return nil, fmt.Errorf("Error fetching Settings: %s", err)
+	}
+	settingsChanges := objectChanges{Type: "Settings"}
+	if fields := compareFields(reflect.ValueOf(*settings), reflect.ValueOf(config.Settings)); len(fields) > 0 {
+		settingsChanges.Changed = append(settingsChanges.Changed, objectChange{Name: "settings", Fields: fields})
+	}
+	changes = append(changes, settingsChanges)
+
+	return changes, nil
+}
+
+// compareObjects compares two slices of named objects, either of which may
+// hold structs or pointers to structs. Objects are matched by name.
+func compareObjects(objectType string, live, desired interface{}) objectChanges {
+	changes := objectChanges{Type: objectType}
+	liveByName := objectsByName(reflect.ValueOf(live))
+	desiredByName := objectsByName(reflect.ValueOf(desired))
+
+	var names []string
+	for name := range liveByName {
+		names = append(names, name)
+	}
+	for name := range desiredByName {
+		if _, ok := liveByName[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		l, inLive := liveByName[name]
+		d, inDesired := desiredByName[name]
+		switch {
+		case !inLive:
+			changes.Added = append(changes.Added, name)
+		case !inDesired:
+			changes.Removed = append(changes.Removed, name)
+		default:
+			if fields := compareFields(l, d); len(fields) > 0 {
+				changes.Changed = append(changes.Changed, objectChange{Name: name, Fields: fields})
+			}
+		}
+	}
+	return changes
+}
+
+func objectsByName(objects reflect.Value) map[string]reflect.Value {
+	byName := make(map[string]reflect.Value)
+	for i := 0; i < objects.Len(); i++ {
+		object := reflect.Indirect(objects.Index(i))
+		if object.IsZero() {
+			continue
+		}
+		byName[object.FieldByName("Name").String()] = object
+	}
+	return byName
+}
+
+// compareFields returns a description of each field which differs between
+// two structs of the same type.
+func compareFields(live, desired reflect.Value) []string {
+	var fields []string
+	for i := 0; i < live.NumField(); i++ {
+		name := live.Type().Field(i).Name
+		if util.StringInSlice(name, ignoredFields) {
+			continue
+		}
+		l, d := live.Field(i).Interface(), desired.Field(i).Interface()
+		if reflect.DeepEqual(l, d) {
+			continue
+		}
+		if util.StringInSlice(name, secretFields) {
+			fields = append(fields, fmt.Sprintf("%s: (changed)", name))
+			continue
+		}
+		fields = append(fields, fmt.Sprintf("%s: %v -> %v", name, l, d))
+	}
+	return fields
+}
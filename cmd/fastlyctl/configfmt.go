@@ -0,0 +1,264 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/alienth/fastlyctl/log"
+	"github.com/alienth/fastlyctl/util"
+	"github.com/urfave/cli"
+)
+
+// multilinePlaceholder stands in for strings containing newlines while a
+// config is encoded, so they can be written as multi-line strings afterwards.
+const multilinePlaceholder = "__fastlyctl_fmt_multiline_%d__"
+
+func configFmt(c *cli.Context) error {
+	files := c.Args()
+	if len(files) == 0 {
+		configFile, err := util.GetConfigFile(c)
+		if err != nil {
+			return cli.NewExitError(err.Error(), -1)
+		}
+		if files, err = util.ConfigFiles(configFile); err != nil {
+			return cli.NewExitError(fmt.Sprintf("Error reading config file: %s", err), -1)
+		}
+	}
+
+	var unformatted []string
+	for _, file := range files {
+		if file == util.StdinConfig || filepath.Ext(file) != ".toml" {
+			// Only TOML configs are formatted. Other files found in a
+			// config directory are left alone.
+			if c.Args().Present() {
+				return cli.NewExitError(fmt.Sprintf("Unable to format %s: only TOML config files can be formatted.", file), -1)
+			}
+			continue
+		}
+		body, err := ioutil.ReadFile(file)
+		if err != nil {
+			return cli.NewExitError(fmt.Sprintf("Error reading config file: %s", err), -1)
+		}
+		formatted, err := formatTOMLConfig(body)
+		if err != nil {
+			return cli.NewExitError(fmt.Sprintf("Unable to format %s: %s", file, err), -1)
+		}
+		if bytes.Equal(body, formatted) {
+			continue
+		}
+		if c.Bool("check") {
+			unformatted = append(unformatted, file)
+			fmt.Println(file)
+			continue
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			return cli.NewExitError(err.Error(), -1)
+		}
+		if err := ioutil.WriteFile(file, formatted, info.Mode()); err != nil {
+			return cli.NewExitError(fmt.Sprintf("Error writing %s: %s", file, err), -1)
+		}
+		log.Info(fmt.Sprintf("Formatted %s\n", file))
+	}
+
+	if len(unformatted) > 0 {
+		return cli.NewExitError(fmt.Sprintf("%d config files are not formatted. Run fastlyctl config fmt to format them.", len(unformatted)), -1)
+	}
+	return nil
+}
+
+// formatTOMLConfig returns body in canonical form: top-level keys first, then
+// tables and keys sorted by name, encoded as service export does. Strings
+// containing newlines are written as multi-line strings. A comment block at
+// the top of the file is kept. Comments anywhere else cannot be placed once
+// the file is reordered, so such files are refused rather than have their
+// comments dropped.
+func formatTOMLConfig(body []byte) ([]byte, error) {
+	header, rest := splitTOMLHeader(string(body))
+	if lines := tomlCommentLines(rest); len(lines) > 0 {
+		offset := strings.Count(header, "\n")
+		var where []string
+		for _, line := range lines {
+			where = append(where, fmt.Sprintf("%d", line+offset))
+		}
+		noun := "line"
+		if len(where) > 1 {
+			noun = "lines"
+		}
+		return nil, fmt.Errorf("the comments on %s %s would be lost. Only comments at the top of the file are kept.", noun, strings.Join(where, ", "))
+	}
+
+	var config map[string]interface{}
+	if _, err := toml.Decode(string(body), &config); err != nil {
+		return nil, fmt.Errorf("toml parsing error: %s", err)
+	}
+
+	var multiline []string
+	value := replaceMultiline(config, &multiline)
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(value); err != nil {
+		return nil, fmt.Errorf("Error encoding config: %s", err)
+	}
+	encoded := buf.String()
+	for i, s := range multiline {
+		encoded = strings.Replace(encoded, fmt.Sprintf(`"`+multilinePlaceholder+`"`, i), multilineString(s), 1)
+	}
+
+	var out bytes.Buffer
+	if header != "" {
+		out.WriteString(strings.TrimRight(header, "\n") + "\n")
+		if encoded != "" {
+			out.WriteString("\n")
+		}
+	}
+	out.WriteString(encoded)
+
+	// Refuse to write anything which does not decode to the same config.
+	var check map[string]interface{}
+	if _, err := toml.Decode(out.String(), &check); err != nil || !reflect.DeepEqual(config, check) {
+		return nil, fmt.Errorf("formatted config does not match the original")
+	}
+	return out.Bytes(), nil
+}
+
+// splitTOMLHeader splits off the comments and blank lines at the top of a
+// TOML document.
+func splitTOMLHeader(body string) (string, string) {
+	var end int
+	for end < len(body) {
+		next := strings.IndexByte(body[end:], '\n')
+		if next < 0 {
+			next = len(body) - end
+		} else {
+			next++
+		}
+		line := strings.TrimSpace(body[end : end+next])
+		if line != "" && !strings.HasPrefix(line, "#") {
+			break
+		}
+		end += next
+	}
+	// Blank lines alone are not a header.
+	header := body[:end]
+	if strings.TrimSpace(header) == "" {
+		header = ""
+	}
+	return header, body[end:]
+}
+
+// tomlCommentLines returns the line numbers of the comments in a TOML
+// document, skipping over strings which may contain a #.
+func tomlCommentLines(body string) []int {
+	var lines []int
+	line := 1
+	for i := 0; i < len(body); i++ {
+		switch {
+		case body[i] == '\n':
+			line++
+		case body[i] == '#':
+			lines = append(lines, line)
+			for i+1 < len(body) && body[i+1] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(body[i:], `"""`), strings.HasPrefix(body[i:], `'''`):
+			delim := body[i : i+3]
+			j := i + 3
+			for j < len(body) && !strings.HasPrefix(body[j:], delim) {
+				if delim == `"""` && body[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			line += strings.Count(body[i:j], "\n")
+			i = j + 2
+		case body[i] == '"', body[i] == '\'':
+			j := i + 1
+			for j < len(body) && body[j] != body[i] && body[j] != '\n' {
+				if body[i] == '"' && body[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			i = j
+		}
+	}
+	return lines
+}
+
+// replaceMultiline returns a copy of v with each string containing a newline
+// replaced by a placeholder, appending the replaced strings to multiline.
+func replaceMultiline(v interface{}, multiline *[]string) interface{} {
+	switch v := v.(type) {
+	case string:
+		if !strings.Contains(v, "\n") {
+			return v
+		}
+		*multiline = append(*multiline, v)
+		return fmt.Sprintf(multilinePlaceholder, len(*multiline)-1)
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			m[key] = replaceMultiline(value, multiline)
+		}
+		return m
+	case []map[string]interface{}:
+		tables := make([]map[string]interface{}, len(v))
+		for i, value := range v {
+			tables[i] = replaceMultiline(value, multiline).(map[string]interface{})
+		}
+		return tables
+	case []interface{}:
+		values := make([]interface{}, len(v))
+		for i, value := range v {
+			values[i] = replaceMultiline(value, multiline)
+		}
+		return values
+	}
+	return v
+}
+
+// multilineString encodes s as a TOML multi-line basic string.
+func multilineString(s string) string {
+	var b bytes.Buffer
+	b.WriteString("\"\"\"\n")
+	var quotes int
+	for _, r := range s {
+		switch {
+		case r == '"':
+			// Escape every third quote in a row, so that no run of
+			// them ends the string.
+			if quotes == 2 {
+				b.WriteString(`\"`)
+				quotes = 0
+				continue
+			}
+			quotes++
+			b.WriteRune(r)
+			continue
+		case r == '\\':
+			b.WriteString(`\\`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\n', r == '\t':
+			b.WriteRune(r)
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(&b, `\u%04x`, r)
+		default:
+			b.WriteRune(r)
+		}
+		quotes = 0
+	}
+	out := b.String()
+	if quotes > 0 {
+		// A quote directly before the closing delimiter would end the
+		// string early.
+		out = out[:len(out)-1] + `\"`
+	}
+	return out + `"""`
+}
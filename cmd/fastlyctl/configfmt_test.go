@@ -0,0 +1,78 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFormatTOMLConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"sorted keys", "b = 1\na = \"x\"\n", "a = \"x\"\nb = 1\n"},
+		{"sorted tables", "include = [\"x.toml\"]\n[b]\nx = 1\n[a]\ny = 2\n", "include = [\"x.toml\"]\n\n[a]\n  y = 2\n\n[b]\n  x = 1\n"},
+		{"header kept", "# top\n# two\n\n\n[s]\na = 1\n", "# top\n# two\n\n[s]\n  a = 1\n"},
+		{"header only", "# only a comment\n", "# only a comment\n"},
+		{"multi-line string", "[s]\nVCL = \"a\\nb\\n\"\n", "[s]\n  VCL = \"\"\"\na\nb\n\"\"\"\n"},
+		{
+			"array of tables",
+			"[s]\n[[s.Backends]]\nName=\"o\"\n[[s.Backends]]\nName=\"p\"\n",
+			"[s]\n\n  [[s.Backends]]\n    Name = \"o\"\n\n  [[s.Backends]]\n    Name = \"p\"\n",
+		},
+		{"hash in string", "[s]\na = \"#1\"\nb = '#2'\n", "[s]\n  a = \"#1\"\n  b = \"#2\"\n"},
+	}
+	for _, test := range tests {
+		got, err := formatTOMLConfig([]byte(test.in))
+		if err != nil {
+			t.Errorf("%s: %s", test.name, err)
+			continue
+		}
+		if string(got) != test.want {
+			t.Errorf("%s: got %q, want %q", test.name, got, test.want)
+			continue
+		}
+		// Formatting is idempotent, so that fmt --check passes on its own
+		// output.
+		again, err := formatTOMLConfig(got)
+		if err != nil || string(again) != string(got) {
+			t.Errorf("%s: formatting twice gave %q, %v", test.name, again, err)
+		}
+	}
+}
+
+func TestFormatTOMLConfigErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{"comment after a key", "a = 1 # one\n"},
+		{"comment between tables", "[s]\na = 1\n# next\n[t]\nb = 2\n"},
+		{"invalid", "a = \n"},
+	}
+	for _, test := range tests {
+		if got, err := formatTOMLConfig([]byte(test.in)); err == nil {
+			t.Errorf("%s: got %q, want an error", test.name, got)
+		}
+	}
+}
+
+func TestTOMLCommentLines(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []int
+	}{
+		{"none", "a = 1\n", nil},
+		{"full line", "a = 1\n# c\n", []int{2}},
+		{"trailing", "a = 1 # c\nb = 2 # d\n", []int{1, 2}},
+		{"in strings", "a = \"#\"\nb = '#'\nc = \"\\\"#\"\n", nil},
+		{"in multi-line strings", "a = \"\"\"\n#\n\"\"\"\nb = '''\n#'''\n# c\n", []int{6}},
+	}
+	for _, test := range tests {
+		if got := tomlCommentLines(test.in); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
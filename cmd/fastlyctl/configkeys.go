@@ -0,0 +1,251 @@
+package main
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// recordUnknownKeys adds keys, which were found in file but map to no config
+// field, to unknownConfigKeys along with their location.
+func recordUnknownKeys(file string, body []byte, keys []string) {
+	sort.Strings(keys)
+	for _, key := range keys {
+		if line := keyLine(body, key); line > 0 {
+			unknownConfigKeys = append(unknownConfigKeys, fmt.Sprintf("%s:%d: unknown key %s", file, line, key))
+		} else {
+			unknownConfigKeys = append(unknownConfigKeys, fmt.Sprintf("%s: unknown key %s", file, key))
+		}
+	}
+}
+
+// checkUnknownKeys fails if any unknown keys were found while reading the
+// config. With lax set they are printed as warnings instead, as fastlyctl
+// has always ignored them.
+func checkUnknownKeys(lax bool) error {
+	if len(unknownConfigKeys) == 0 {
+		return nil
+	}
+	if lax {
+		for _, key := range unknownConfigKeys {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", key)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown keys in config, which may be misspelled:\n  %s\nUse --lax to ignore them.", strings.Join(unknownConfigKeys, "\n  "))
+}
+
+// undecodedTOMLKeys returns the keys TOML decoding did not use. Keys within an
+// unused table are left out in favour of the table itself.
+func undecodedTOMLKeys(md toml.MetaData) []string {
+	var keys []string
+	unknown := make(map[string]bool)
+	for _, key := range md.Undecoded() {
+		if key[0] == "profiles" {
+			continue
+		}
+		nested := false
+		for i := 1; i < len(key); i++ {
+			if unknown[key[:i].String()] {
+				nested = true
+				break
+			}
+		}
+		if nested {
+			continue
+		}
+		unknown[key.String()] = true
+		keys = append(keys, key.String())
+	}
+	return keys
+}
+
+// unknownJSONKeys returns the keys in v, a decoded JSON value, which would be
+// ignored when decoding it into a value of type t. Field names are matched
+// case-insensitively, as encoding/json does.
+func unknownJSONKeys(v interface{}, t reflect.Type, path string) []string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	// Types which decode themselves are trusted to handle their own keys.
+	if reflect.PtrTo(t).Implements(jsonUnmarshalerType) || reflect.PtrTo(t).Implements(textUnmarshalerType) {
+		return nil
+	}
+
+	var keys []string
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			switch t.Kind() {
+			case reflect.Map:
+				keys = append(keys, unknownJSONKeys(value, t.Elem(), path+"."+key)...)
+			case reflect.Struct:
+				field, ok := jsonField(t, key)
+				if !ok {
+					keys = append(keys, path+"."+key)
+					continue
+				}
+				keys = append(keys, unknownJSONKeys(value, field.Type, path+"."+key)...)
+			}
+		}
+	case []interface{}:
+		if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			for i, value := range v {
+				keys = append(keys, unknownJSONKeys(value, t.Elem(), fmt.Sprintf("%s[%d]", path, i))...)
+			}
+		}
+	}
+	return keys
+}
+
+// jsonField finds the field of struct type t which encoding/json would decode
+// key into, including fields of embedded structs.
+func jsonField(t reflect.Type, key string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		if field.Anonymous && tag == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				if f, ok := jsonField(embedded, key); ok {
+					return f, true
+				}
+			}
+			continue
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if name == "" {
+			name = field.Name
+		}
+		if strings.EqualFold(name, key) {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// definedTOMLFields returns the fields set by each service in a TOML config.
+func definedTOMLFields(md toml.MetaData) map[string][]string {
+	defined := make(map[string][]string)
+	for _, key := range md.Keys() {
+		if len(key) < 2 {
+			continue
+		}
+		t := reflect.TypeOf(SiteConfig{})
+		var path []string
+		for _, name := range key[1:] {
+			if t.Kind() != reflect.Struct {
+				// Keys within a list are part of the list value.
+				path = nil
+				break
+			}
+			field, ok := tomlField(t, name)
+			if !ok {
+				path = nil
+				break
+			}
+			path = append(path, field.Name)
+			t = field.Type
+		}
+		// A table is merged key by key, so only its keys are recorded.
+		if path != nil && t.Kind() != reflect.Struct {
+			defined[key[0]] = append(defined[key[0]], strings.Join(path, "."))
+		}
+	}
+	return defined
+}
+
+// tomlField finds the field of struct type t which the TOML decoder would
+// decode key into.
+func tomlField(t reflect.Type, key string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name := field.Tag.Get("toml")
+		if name == "" {
+			name = field.Name
+		}
+		if strings.EqualFold(name, key) {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// definedJSONFields returns the paths of the fields of struct type t set in
+// v, a decoded JSON object. Nested objects are descended into, as a table is
+// merged key by key.
+func definedJSONFields(v interface{}, t reflect.Type, path string) []string {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	var fields []string
+	for key, value := range m {
+		field, ok := jsonField(t, key)
+		if !ok {
+			continue
+		}
+		name := path + field.Name
+		if field.Type.Kind() == reflect.Struct {
+			fields = append(fields, definedJSONFields(value, field.Type, name+".")...)
+			continue
+		}
+		fields = append(fields, name)
+	}
+	return fields
+}
+
+// keyLine makes a best effort at finding the line of body on which the last
+// component of key is defined, returning 0 if it cannot be found.
+func keyLine(body []byte, key string) int {
+	name := key
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	if i := strings.Index(name, "["); i >= 0 {
+		name = name[:i]
+	}
+	for i, line := range strings.Split(string(body), "\n") {
+		line = strings.TrimSpace(line)
+		// The first key of a YAML list item follows a dash.
+		key := strings.TrimPrefix(line, "- ")
+		if rest := strings.TrimPrefix(key, name); rest != key {
+			// A TOML or YAML key.
+			if rest = strings.TrimSpace(rest); strings.HasPrefix(rest, "=") || strings.HasPrefix(rest, ":") {
+				return i + 1
+			}
+		}
+		if j := strings.Index(line, `"`+name+`"`); j >= 0 {
+			// A JSON key.
+			if rest := strings.TrimSpace(line[j+len(name)+2:]); strings.HasPrefix(rest, ":") {
+				return i + 1
+			}
+		}
+		if strings.HasPrefix(line, "[") && (strings.Contains(line, "."+name+"]") || strings.Contains(line, "["+name+"]")) {
+			// A TOML table header.
+			return i + 1
+		}
+	}
+	return 0
+}
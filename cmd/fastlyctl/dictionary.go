@@ -1,8 +1,20 @@
 package main
 
 import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"sync"
 
+	"github.com/alienth/fastlyctl/fastlyapi"
+	"github.com/alienth/fastlyctl/format"
+	"github.com/alienth/fastlyctl/log"
 	"github.com/alienth/fastlyctl/util"
 	"github.com/alienth/go-fastly"
 	"github.com/urfave/cli"
@@ -14,7 +26,7 @@ func dictionaryList(c *cli.Context) error {
 	var err error
 	serviceParam := c.Args().Get(0)
 	var service *fastly.Service
-	if service, _, err = client.Service.Search(serviceParam); err != nil {
+	if service, err = util.GetService(client, serviceParam); err != nil {
 		return cli.NewExitError(err.Error(), -1)
 	}
 	activeVersion, err := util.GetActiveVersion(service)
@@ -22,17 +34,51 @@ func dictionaryList(c *cli.Context) error {
 		return cli.NewExitError(err.Error(), -1)
 	}
 
-	dictionaries, _, err := client.Dictionary.List(service.ID, activeVersion)
+	dictionaries, _, err := fastlyapi.New(client).Dictionary.List(service.ID, activeVersion)
 	if err != nil {
-		return cli.NewExitError(fmt.Sprintf("Unable to list dictionaries for service %s\n", service.Name), -1)
+		return cli.NewExitError(fmt.Sprintf("Unable to list dictionaries for service %s: %s", service.Name, err), -1)
 	}
+	if outputFormat(c) != format.Table {
+		output := make([]dictionaryOutput, 0, len(dictionaries))
+		for _, d := range dictionaries {
+			output = append(output, newDictionaryOutput(d))
+		}
+		return printOutput(c, output)
+	}
+
 	fmt.Printf("Dictionaries for %s:\n\n", service.Name)
 	for _, d := range dictionaries {
-		fmt.Println(d.Name)
+		if d.WriteOnly {
+			fmt.Println(d.Name, "(write-only)")
+		} else {
+			fmt.Println(d.Name)
+		}
 	}
 	return nil
 }
 
+// writeOnlyValue is shown in place of the values of items in write-only
+// dictionaries, which cannot be read back.
+const writeOnlyValue = "<write-only>"
+
+// listDictionaryItems lists the items of a dictionary. The values of items in
+// write-only dictionaries are replaced with writeOnlyValue.
+func listDictionaryItems(client *fastly.Client, dictionary *fastlyapi.Dictionary) ([]*fastly.DictionaryItem, error) {
+	items, _, err := fastlyapi.New(client).DictionaryItem.List(dictionary.ServiceID, dictionary.ID)
+	if err != nil {
+		if dictionary.WriteOnly {
+			return nil, fmt.Errorf("Unable to list items of write-only dictionary %s: %s", dictionary.Name, err)
+		}
+		return nil, err
+	}
+	if dictionary.WriteOnly {
+		for _, item := range items {
+			item.Value = writeOnlyValue
+		}
+	}
+	return items, nil
+}
+
 func dictionaryAddItem(c *cli.Context) error {
 	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
 
@@ -50,6 +96,24 @@ func dictionaryAddItem(c *cli.Context) error {
 	item.Key = keyParam
 	item.Value = valueParam
 
+	if c.Bool("upsert") && dictionary.WriteOnly {
+		// Items of write-only dictionaries may not be readable, so the
+		// update is attempted first rather than checking for the item.
+		_, resp, err := client.DictionaryItem.Update(dictionary.ServiceID, dictionary.ID, keyParam, item)
+		if err == nil {
+			return nil
+		} else if resp == nil || resp.StatusCode != 404 {
+			return cli.NewExitError(err.Error(), -1)
+		}
+	} else if c.Bool("upsert") {
+		if _, _, err = client.DictionaryItem.Get(dictionary.ServiceID, dictionary.ID, keyParam); err == nil {
+			if _, _, err = client.DictionaryItem.Update(dictionary.ServiceID, dictionary.ID, keyParam, item); err != nil {
+				return cli.NewExitError(err.Error(), -1)
+			}
+			return nil
+		}
+	}
+
 	if _, _, err = client.DictionaryItem.Create(dictionary.ServiceID, dictionary.ID, item); err != nil {
 		return cli.NewExitError(err.Error(), -1)
 	}
@@ -57,6 +121,44 @@ func dictionaryAddItem(c *cli.Context) error {
 	return nil
 }
 
+func dictionaryUpdateItem(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+
+	serviceParam := c.Args().Get(0)
+	dictParam := c.Args().Get(1)
+	keyParam := c.Args().Get(2)
+	valueParam := c.Args().Get(3)
+
+	dictionary, err := util.GetDictionaryByName(client, serviceParam, dictParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	// Items of write-only dictionaries may not be readable, in which case
+	// a missing item is reported by the update itself.
+	if !dictionary.WriteOnly {
+		if _, resp, err := client.DictionaryItem.Get(dictionary.ServiceID, dictionary.ID, keyParam); err != nil {
+			if resp != nil && resp.StatusCode == 404 {
+				return cli.NewExitError(fmt.Sprintf("Item %s does not exist in dictionary %s. Use item-add to create it.", keyParam, dictParam), -1)
+			}
+			return cli.NewExitError(err.Error(), -1)
+		}
+	}
+
+	item := new(fastly.DictionaryItem)
+	item.Key = keyParam
+	item.Value = valueParam
+
+	if _, resp, err := client.DictionaryItem.Update(dictionary.ServiceID, dictionary.ID, keyParam, item); err != nil {
+		if resp != nil && resp.StatusCode == 404 {
+			return cli.NewExitError(fmt.Sprintf("Item %s does not exist in dictionary %s. Use item-add to create it.", keyParam, dictParam), -1)
+		}
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	return nil
+}
+
 func dictionaryRemoveItem(c *cli.Context) error {
 	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
 
@@ -87,11 +189,23 @@ func dictionaryListItems(c *cli.Context) error {
 		return cli.NewExitError(err.Error(), -1)
 	}
 
-	items, _, err := client.DictionaryItem.List(dictionary.ServiceID, dictionary.ID)
+	items, err := listDictionaryItems(client, dictionary)
 	if err != nil {
 		return cli.NewExitError(err.Error(), -1)
 	}
 
+	if c.Bool("count") {
+		fmt.Println(len(items))
+		return nil
+	}
+	if outputFormat(c) != format.Table {
+		output := make([]dictionaryItemOutput, 0, len(items))
+		for _, item := range items {
+			output = append(output, newDictionaryItemOutput(item))
+		}
+		return printOutput(c, output)
+	}
+
 	fmt.Printf("Items in dictionary %s for service %s:\n\n", dictParam, serviceParam)
 	for _, item := range items {
 		fmt.Println(item.Key, item.Value)
@@ -99,3 +213,464 @@ func dictionaryListItems(c *cli.Context) error {
 
 	return nil
 }
+
+func dictionaryCreate(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+	serviceParam := c.Args().Get(0)
+	dictParam := c.Args().Get(1)
+
+	service, err := util.GetService(client, serviceParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	version, err := draftVersion(c, client, service)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	dictionary := new(fastlyapi.Dictionary)
+	dictionary.Name = dictParam
+	dictionary.WriteOnly = c.Bool("write-only")
+	if _, _, err = fastlyapi.New(client).Dictionary.Create(service.ID, version, dictionary); err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error creating dictionary: %s", err), -1)
+	}
+	fmt.Printf("Created dictionary %s on version %d of service %s. Validate and activate version %d to apply.\n", dictParam, version, service.Name, version)
+
+	return nil
+}
+
+func dictionaryDelete(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+	serviceParam := c.Args().Get(0)
+	dictParam := c.Args().Get(1)
+
+	service, err := util.GetService(client, serviceParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	if !c.GlobalBool("assume-yes") {
+		proceed, err := util.Prompt(fmt.Sprintf("Delete dictionary %s and all of its items from service %s?", dictParam, service.Name))
+		if err != nil {
+			return cli.NewExitError(err.Error(), -1)
+		}
+		if !proceed {
+			return nil
+		}
+	}
+
+	version, err := draftVersion(c, client, service)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	if _, err = client.Dictionary.Delete(service.ID, version, dictParam); err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error deleting dictionary: %s", err), -1)
+	}
+	fmt.Printf("Deleted dictionary %s from version %d of service %s. Validate and activate version %d to apply.\n", dictParam, version, service.Name, version)
+
+	return nil
+}
+
+// dictionaryBatchSize is the maximum number of items Fastly accepts in a
+// single batch update.
+const dictionaryBatchSize = 1000
+
+// readDictionaryFile reads dictionary items from a file. Files ending in .csv
+// are read as key,value records. Files ending in .json hold either an object
+// of keys to values, or the list of items written by item-export --json.
+// Otherwise each line is a key=value pair, and blank lines and lines beginning
+// with # are ignored.
+//
+// Files exported from write-only dictionaries hold placeholders rather than
+// values, and are refused so that the real values are not overwritten.
+func readDictionaryFile(file string) (map[string]string, error) {
+	items, err := parseDictionaryFile(file)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range items {
+		if value == writeOnlyValue {
+			return nil, fmt.Errorf("%s: item %s has the placeholder value %s, as exported from a write-only dictionary", file, key, writeOnlyValue)
+		}
+	}
+	return items, nil
+}
+
+func parseDictionaryFile(file string) (map[string]string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	items := make(map[string]string)
+	if strings.HasSuffix(file, ".json") {
+		body, err := ioutil.ReadAll(f)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(body, &items); err == nil {
+			return items, nil
+		}
+		var list []dictionaryItemOutput
+		if err := json.Unmarshal(body, &list); err != nil {
+			return nil, fmt.Errorf("expected an object of keys to string values, or a list of items: %s", err)
+		}
+		for _, item := range list {
+			items[item.Key] = item.Value
+		}
+		return items, nil
+	}
+	if strings.HasSuffix(file, ".csv") {
+		reader := csv.NewReader(f)
+		reader.FieldsPerRecord = 2
+		for {
+			record, err := reader.Read()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				return nil, err
+			}
+			items[record[0]] = record[1]
+		}
+		return items, nil
+	}
+
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		split := strings.SplitN(text, "=", 2)
+		if len(split) != 2 {
+			return nil, fmt.Errorf("%s:%d: expected key=value", file, line)
+		}
+		items[split[0]] = split[1]
+	}
+	return items, scanner.Err()
+}
+
+func dictionaryBulkItems(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+
+	serviceParam := c.Args().Get(0)
+	dictParam := c.Args().Get(1)
+	fileParam := c.Args().Get(2)
+
+	newItems, err := readDictionaryFile(fileParam)
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error reading %s: %s", fileParam, err), -1)
+	}
+
+	dictionary, err := util.GetDictionaryByName(client, serviceParam, dictParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	existingItems, _, err := fastlyapi.New(client).DictionaryItem.List(dictionary.ServiceID, dictionary.ID)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	warnWriteOnlyItems(dictionary)
+	changes := diffDictionaryItems(existingItems, newItems, c.Bool("delete-missing"), dictionary.WriteOnly)
+	if failures := applyDictionaryChanges(client, dictionary, changes.updates, c.Int("concurrency")); len(failures) > 0 {
+		return dictionaryFailures(dictParam, len(changes.updates), failures)
+	}
+	log.Info(fmt.Sprintf("Dictionary %s: %d items created, %d updated, %d deleted\n", dictParam, changes.created, changes.updated, changes.deleted))
+
+	return nil
+}
+
+// dictionaryChanges holds the batch operations needed to bring a dictionary
+// in line with a set of items.
+type dictionaryChanges struct {
+	updates                   []fastly.DictionaryItemUpdate
+	created, updated, deleted int
+}
+
+// diffDictionaryItems compares the existing items of a dictionary against
+// newItems. Existing items missing from newItems are only deleted if
+// deleteMissing is set. The values of a write-only dictionary cannot be read,
+// so its existing items are never updated.
+func diffDictionaryItems(existingItems []*fastly.DictionaryItem, newItems map[string]string, deleteMissing, writeOnly bool) dictionaryChanges {
+	var changes dictionaryChanges
+	existing := make(map[string]bool)
+	for _, item := range existingItems {
+		existing[item.Key] = true
+		if value, ok := newItems[item.Key]; ok {
+			if value != item.Value && !writeOnly {
+				changes.updates = append(changes.updates, fastly.DictionaryItemUpdate{Operation: fastly.BatchOperationUpdate, Key: item.Key, Value: value})
+				changes.updated++
+			}
+		} else if deleteMissing {
+			changes.updates = append(changes.updates, fastly.DictionaryItemUpdate{Operation: fastly.BatchOperationDelete, Key: item.Key})
+			changes.deleted++
+		}
+	}
+	for key, value := range newItems {
+		if !existing[key] {
+			changes.updates = append(changes.updates, fastly.DictionaryItemUpdate{Operation: fastly.BatchOperationCreate, Key: key, Value: value})
+			changes.created++
+		}
+	}
+	return changes
+}
+
+// applyDictionaryChanges sends updates in as few batch requests as Fastly
+// allows, with up to concurrency requests in flight at once. Nothing is sent
+// if there are no updates. A batch rejected as invalid is retried one item at
+// a time, so that a single bad item does not hold back the rest. A description of each
+// item which could not be applied is returned.
+func applyDictionaryChanges(client *fastly.Client, dictionary *fastlyapi.Dictionary, updates []fastly.DictionaryItemUpdate, concurrency int) []string {
+	var batches [][]fastly.DictionaryItemUpdate
+	for i := 0; i < len(updates); i += dictionaryBatchSize {
+		end := i + dictionaryBatchSize
+		if end > len(updates) {
+			end = len(updates)
+		}
+		batches = append(batches, updates[i:end])
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var progress *util.Progress
+	if len(batches) > 1 {
+		progress = util.NewProgress(len(batches), true)
+	}
+
+	var failures []string
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	work := make(chan []fastly.DictionaryItemUpdate)
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range work {
+				failed := applyDictionaryBatch(client, dictionary, batch)
+				if len(failed) > 0 {
+					mu.Lock()
+					failures = append(failures, failed...)
+					mu.Unlock()
+				}
+				if progress != nil {
+					progress.Done(dictionary.Name, fmt.Sprintf("wrote %d of %d items", len(batch)-len(failed), len(batch)))
+				}
+			}
+		}()
+	}
+	for _, batch := range batches {
+		work <- batch
+	}
+	close(work)
+	wg.Wait()
+	if progress != nil {
+		progress.Finish()
+	}
+
+	sort.Strings(failures)
+	return failures
+}
+
+// applyDictionaryBatch sends a single batch of updates. If the batch is
+// rejected as invalid, each item is retried on its own to find the bad ones.
+// Rate limit and server errors are retried by util.WithRetry, and fail the
+// whole batch if they persist. A description of each item which could not be
+// applied is returned.
+func applyDictionaryBatch(client *fastly.Client, dictionary *fastlyapi.Dictionary, batch []fastly.DictionaryItemUpdate) []string {
+	send := func(updates []fastly.DictionaryItemUpdate) error {
+		return util.WithRetry(func() error {
+			_, err := fastlyapi.New(client).DictionaryItem.BatchUpdate(dictionary.ServiceID, dictionary.ID, updates)
+			return err
+		})
+	}
+	err := send(batch)
+	if err == nil {
+		return nil
+	}
+	var failures []string
+	if len(batch) == 1 || !isValidationError(err) {
+		for _, update := range batch {
+			failures = append(failures, describeFailure(update, err))
+		}
+		return failures
+	}
+	log.Debug(fmt.Sprintf("Batch of %d items failed, retrying individually: %s\n", len(batch), err))
+	for i := range batch {
+		if err := send(batch[i : i+1]); err != nil {
+			failures = append(failures, describeFailure(batch[i], err))
+		}
+	}
+	return failures
+}
+
+// isValidationError reports whether err is a 4xx response other than rate
+// limiting, meaning Fastly rejected the request itself.
+func isValidationError(err error) bool {
+	e, ok := err.(*fastly.ErrorResponse)
+	return ok && e.Response != nil && e.Response.StatusCode >= 400 && e.Response.StatusCode < 500 && e.Response.StatusCode != 429
+}
+
+// warnWriteOnlyItems explains that the existing items of a write-only
+// dictionary are left alone by diffDictionaryItems.
+func warnWriteOnlyItems(dictionary *fastlyapi.Dictionary) {
+	if dictionary.WriteOnly {
+		fmt.Fprintf(os.Stderr, "Dictionary %s is write-only, so existing items cannot be compared and will not be updated. Only missing items are created and unlisted items deleted. Use item-update to change a value.\n", dictionary.Name)
+	}
+}
+
+func describeFailure(update fastly.DictionaryItemUpdate, err error) string {
+	op, _ := update.Operation.MarshalText()
+	return fmt.Sprintf("%s %s: %s", op, update.Key, err)
+}
+
+// dictionaryFailures builds the error returned when some changes to a
+// dictionary could not be applied.
+func dictionaryFailures(dictionary string, total int, failures []string) error {
+	return cli.NewExitError(fmt.Sprintf("Applied %d of %d changes to dictionary %s. Failed:\n  %s", total-len(failures), total, dictionary, strings.Join(failures, "\n  ")), -1)
+}
+
+func dictionarySync(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+
+	serviceParam := c.Args().Get(0)
+	dictParam := c.Args().Get(1)
+	fileParam := c.Args().Get(2)
+
+	newItems, err := readDictionaryFile(fileParam)
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error reading %s: %s", fileParam, err), -1)
+	}
+
+	dictionary, err := util.GetDictionaryByName(client, serviceParam, dictParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	existingItems, _, err := fastlyapi.New(client).DictionaryItem.List(dictionary.ServiceID, dictionary.ID)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	warnWriteOnlyItems(dictionary)
+	changes := diffDictionaryItems(existingItems, newItems, true, dictionary.WriteOnly)
+	if len(changes.updates) == 0 {
+		log.Info(fmt.Sprintf("Dictionary %s already matches %s (%d items). No changes made.\n", dictParam, fileParam, len(existingItems)))
+		return nil
+	}
+	if failures := applyDictionaryChanges(client, dictionary, changes.updates, c.Int("concurrency")); len(failures) > 0 {
+		return dictionaryFailures(dictParam, len(changes.updates), failures)
+	}
+	log.Info(fmt.Sprintf("Dictionary %s synced with %s: %d items before, %d after. %d created, %d updated, %d deleted.\n", dictParam, fileParam, len(existingItems), len(newItems), changes.created, changes.updated, changes.deleted))
+
+	return nil
+}
+
+// dictionaryCopy copies every item of one dictionary into another, which may
+// be on another service.
+func dictionaryCopy(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+
+	srcService, srcDict := c.Args().Get(0), c.Args().Get(1)
+	dstService, dstDict := c.Args().Get(2), c.Args().Get(3)
+
+	src, err := util.GetDictionaryByName(client, srcService, srcDict)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	if src.WriteOnly {
+		return cli.NewExitError(fmt.Sprintf("Dictionary %s is write-only, so its values cannot be copied.", srcDict), -1)
+	}
+	dst, err := util.GetDictionaryByName(client, dstService, dstDict)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	srcItems, _, err := fastlyapi.New(client).DictionaryItem.List(src.ServiceID, src.ID)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	dstItems, _, err := fastlyapi.New(client).DictionaryItem.List(dst.ServiceID, dst.ID)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	existing := make(map[string]string, len(dstItems))
+	for _, item := range dstItems {
+		existing[item.Key] = item.Value
+	}
+
+	var updates []fastly.DictionaryItemUpdate
+	var created, updated, unchanged, skipped int
+	for _, item := range srcItems {
+		value, ok := existing[item.Key]
+		switch {
+		case !ok:
+			updates = append(updates, fastly.DictionaryItemUpdate{Operation: fastly.BatchOperationCreate, Key: item.Key, Value: item.Value})
+			created++
+		// The values of a write-only destination are unknown, so its
+		// items are always overwritten when asked.
+		case value == item.Value && !dst.WriteOnly:
+			unchanged++
+		case c.Bool("overwrite"):
+			updates = append(updates, fastly.DictionaryItemUpdate{Operation: fastly.BatchOperationUpdate, Key: item.Key, Value: item.Value})
+			updated++
+		default:
+			skipped++
+		}
+	}
+
+	if failures := applyDictionaryChanges(client, dst, updates, c.Int("concurrency")); len(failures) > 0 {
+		return dictionaryFailures(dstDict, len(updates), failures)
+	}
+	log.Info(fmt.Sprintf("Copied %d items from %s/%s to %s/%s: %d created, %d overwritten, %d unchanged, %d existing items skipped.\n", len(srcItems), srcService, srcDict, dstService, dstDict, created, updated, unchanged, skipped))
+	if skipped > 0 {
+		log.Info("Use --overwrite to replace the values of existing items.\n")
+	}
+
+	return nil
+}
+
+func dictionaryExportItems(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+
+	serviceParam := c.Args().Get(0)
+	dictParam := c.Args().Get(1)
+
+	dictionary, err := util.GetDictionaryByName(client, serviceParam, dictParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	items, err := listDictionaryItems(client, dictionary)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	// Sort explicitly so that exports of the same dictionary are identical.
+	sort.Slice(items, func(i, j int) bool { return items[i].Key < items[j].Key })
+
+	if c.Bool("json") {
+		output := make([]dictionaryItemOutput, 0, len(items))
+		for _, item := range items {
+			output = append(output, newDictionaryItemOutput(item))
+		}
+		return format.Write(os.Stdout, format.JSON, output)
+	}
+
+	writer := csv.NewWriter(os.Stdout)
+	for _, item := range items {
+		if err = writer.Write([]string{item.Key, item.Value}); err != nil {
+			return cli.NewExitError(err.Error(), -1)
+		}
+	}
+	writer.Flush()
+	if err = writer.Error(); err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	return nil
+}
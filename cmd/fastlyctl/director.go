@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alienth/fastlyctl/fastlyapi"
+	"github.com/alienth/fastlyctl/format"
+	"github.com/alienth/fastlyctl/log"
+	"github.com/alienth/fastlyctl/util"
+	"github.com/alienth/go-fastly"
+	"github.com/urfave/cli"
+)
+
+func directorList(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+
+	serviceParam := c.Args().Get(0)
+	service, err := util.GetService(client, serviceParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	activeVersion, err := util.GetActiveVersion(service)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	directors, _, err := fastlyapi.New(client).Director.List(service.ID, activeVersion)
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Unable to list directors for service %s: %s", service.Name, err), -1)
+	}
+	if outputFormat(c) != format.Table {
+		output := make([]directorOutput, 0, len(directors))
+		for _, d := range directors {
+			output = append(output, newDirectorOutput(d))
+		}
+		return printOutput(c, output)
+	}
+
+	fmt.Printf("Directors for %s:\n\n", service.Name)
+	fmt.Printf("%-30s %-8s %6s  %s\n", "Name", "Type", "Quorum", "Backends")
+	for _, d := range directors {
+		fmt.Printf("%-30s %-8s %5d%%  %s\n", d.Name, d.Type, d.Quorum, dashIfEmpty(strings.Join(d.Backends, ",")))
+	}
+	return nil
+}
+
+// checkDirectorAdd is used as the Before hook for director add.
+func checkDirectorAdd(c *cli.Context) error {
+	if c.NArg() < 2 {
+		return cli.NewExitError("Please specify director name.", -1)
+	}
+	if _, ok := fastlyapi.DirectorTypes[c.String("type")]; !ok {
+		return cli.NewExitError(fmt.Sprintf("Invalid director type %q. Must be one of: random, hash, client", c.String("type")), -1)
+	}
+	if c.Int("quorum") < 0 || c.Int("quorum") > 100 {
+		return cli.NewExitError("Quorum must be a percentage between 0 and 100.", -1)
+	}
+	return checkActivate(c)
+}
+
+func directorAdd(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+	api := fastlyapi.New(client)
+
+	serviceParam := c.Args().Get(0)
+	nameParam := c.Args().Get(1)
+
+	service, err := util.GetService(client, serviceParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	director := new(fastlyapi.Director)
+	director.Name = nameParam
+	director.Type = fastlyapi.DirectorTypes[c.String("type")]
+	director.Quorum = uint(c.Int("quorum"))
+	director.Retries = uint(c.Int("retries"))
+	director.Comment = c.String("comment")
+
+	version, err := draftVersion(c, client, service)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	if _, _, err = api.Director.Create(service.ID, version, director); err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error creating director: %s", err), -1)
+	}
+	log.Info(fmt.Sprintf("Created director %s\n", nameParam))
+
+	// Member backends are attached one at a time after the director exists.
+	for _, backend := range strings.FieldsFunc(c.String("backends"), func(r rune) bool { return r == ',' || r == ' ' }) {
+		if _, err = api.Director.AddBackend(service.ID, version, nameParam, backend); err != nil {
+			return cli.NewExitError(fmt.Sprintf("Error adding backend %s to director %s on draft version %d: %s", backend, nameParam, version, err), -1)
+		}
+		log.Info(fmt.Sprintf("Added backend %s to director %s\n", backend, nameParam))
+	}
+
+	if err = finishDraft(c, client, service, version); err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	return nil
+}
+
+func directorRemove(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+
+	serviceParam := c.Args().Get(0)
+	nameParam := c.Args().Get(1)
+
+	service, err := util.GetService(client, serviceParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	version, err := draftVersion(c, client, service)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	if _, err = fastlyapi.New(client).Director.Delete(service.ID, version, nameParam); err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error removing director: %s", err), -1)
+	}
+	log.Info(fmt.Sprintf("Removed director %s\n", nameParam))
+
+	if err = finishDraft(c, client, service, version); err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	return nil
+}
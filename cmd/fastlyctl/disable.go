@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/alienth/fastlyctl/log"
+	"github.com/alienth/fastlyctl/util"
+	"github.com/alienth/go-fastly"
+	"github.com/urfave/cli"
+)
+
+// Fastly has no way of disabling a service as such. Instead, service disable
+// deactivates the active version and records it in a local state file, so
+// that service enable knows which version to bring back.
+
+// disabledStateFile returns the path of the file recording the version each
+// disabled service was serving.
+func disabledStateFile() string {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" {
+		dir = filepath.Join(os.Getenv("HOME"), ".local", "state")
+	}
+	return filepath.Join(dir, "fastlyctl", "disabled.json")
+}
+
+// readDisabledState returns the disabled versions recorded in the state file,
+// keyed by service ID.
+func readDisabledState() (map[string]uint, error) {
+	state := make(map[string]uint)
+	body, err := ioutil.ReadFile(disabledStateFile())
+	if os.IsNotExist(err) {
+		return state, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(body, &state); err != nil {
+		return nil, fmt.Errorf("Error parsing %s: %s", disabledStateFile(), err)
+	}
+	return state, nil
+}
+
+func writeDisabledState(state map[string]uint) error {
+	file := disabledStateFile()
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	body, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, append(body, '\n'), 0644)
+}
+
+func serviceDisable(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+
+	service, err := util.GetService(client, c.Args().Get(0))
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	activeVersion, err := util.GetActiveVersion(service)
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Service %s has no active version, so is already disabled.", service.Name), -1)
+	}
+	// Read the state first, so that a broken state file is found before
+	// any traffic is taken offline.
+	state, err := readDisabledState()
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	if util.DryRun() {
+		fmt.Printf("Dry run: would disable service %s by deactivating version %d.\n", service.Name, activeVersion)
+		return nil
+	}
+
+	if !c.GlobalBool("assume-yes") {
+		question := fmt.Sprintf("Disable service %s by deactivating version %d? The service will stop serving traffic.", service.Name, activeVersion)
+		proceed, err := util.Prompt(question)
+		if err != nil {
+			return cli.NewExitError(err.Error(), -1)
+		}
+		if !proceed {
+			return nil
+		}
+	}
+
+	if _, _, err = client.Version.Deactivate(service.ID, activeVersion); err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error deactivating version: %s", err), -1)
+	}
+	util.ForgetService(service)
+	log.Info(fmt.Sprintf("Service %s disabled. Version %d was deactivated.\n", service.Name, activeVersion))
+
+	state[service.ID] = activeVersion
+	if err = writeDisabledState(state); err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error recording disabled version in %s: %s\nRe-activate version %d to enable the service.", disabledStateFile(), err, activeVersion), -1)
+	}
+	return nil
+}
+
+func serviceEnable(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+
+	service, err := util.GetService(client, c.Args().Get(0))
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	if activeVersion, err := util.GetActiveVersion(service); err == nil {
+		return cli.NewExitError(fmt.Sprintf("Service %s is already serving version %d.", service.Name, activeVersion), -1)
+	}
+	state, err := readDisabledState()
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	version, ok := state[service.ID]
+	if !ok {
+		// The service was disabled elsewhere, so fall back to the
+		// latest version Fastly reports as deployed.
+		deployed := lastDeployed(service, 0)
+		if deployed == nil {
+			return cli.NewExitError(fmt.Sprintf("Unable to find a previously active version of service %s. Use version activate instead.", service.Name), -1)
+		}
+		version = deployed.Number
+		log.Info(fmt.Sprintf("No disabled version recorded for %s, using the latest deployed version %d.\n", service.Name, version))
+	}
+
+	if util.DryRun() {
+		fmt.Printf("Dry run: would enable service %s by activating version %d.\n", service.Name, version)
+		return nil
+	}
+
+	if !c.GlobalBool("assume-yes") {
+		proceed, err := util.Prompt(fmt.Sprintf("Enable service %s by activating version %d?", service.Name, version))
+		if err != nil {
+			return cli.NewExitError(err.Error(), -1)
+		}
+		if !proceed {
+			return nil
+		}
+	}
+
+	err = util.WithRetry(func() error {
+		_, _, err := client.Version.Activate(service.ID, version)
+		return err
+	})
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error activating version: %s", err), -1)
+	}
+	util.ForgetService(service)
+	log.Info(fmt.Sprintf("Service %s enabled. Version %d is active.\n", service.Name, version))
+
+	if ok {
+		delete(state, service.ID)
+		if err = writeDisabledState(state); err != nil {
+			return cli.NewExitError(fmt.Sprintf("Error updating %s: %s", disabledStateFile(), err), -1)
+		}
+	}
+	return nil
+}
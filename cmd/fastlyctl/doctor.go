@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alienth/fastlyctl/util"
+	"github.com/urfave/cli"
+)
+
+// redactKey hides all but the last four characters of an API key, which is
+// enough to tell keys apart.
+func redactKey(key string) string {
+	if len(key) <= 8 {
+		return strings.Repeat("*", len(key))
+	}
+	return strings.Repeat("*", len(key)-4) + key[len(key)-4:]
+}
+
+// doctor checks the config, API key, connectivity and pager, printing PASS or
+// FAIL for each. It runs without the usual API key check, so that a missing
+// key is reported rather than aborting.
+func doctor(c *cli.Context) error {
+	var failed int
+	report := func(name string, err error, detail string) {
+		if err != nil {
+			failed++
+			fmt.Printf("[FAIL] %-7s %s\n", name+":", strings.Replace(strings.TrimSpace(err.Error()), "\n", "\n                ", -1))
+			return
+		}
+		fmt.Printf("[PASS] %-7s %s\n", name+":", detail)
+	}
+
+	if file, err := util.GetConfigFile(c); err != nil {
+		report("config", err, "")
+	} else if err := readConfig(file); err != nil {
+		report("config", fmt.Errorf("%s does not parse: %s", file, err), "")
+	} else if err := checkUnknownKeys(c.GlobalBool("lax")); err != nil {
+		report("config", fmt.Errorf("%s: %s", file, err), "")
+	} else {
+		services := 0
+		for name := range siteConfigs {
+			if name != "_default_" {
+				services++
+			}
+		}
+		report("config", nil, fmt.Sprintf("%s parses, defining %d services", file, services))
+	}
+
+	keyErr := util.CheckFastlyKey(c)
+	if keyErr != nil {
+		report("key", fmt.Errorf("%s", strings.TrimPrefix(keyErr.Error(), "Error: ")), "")
+	} else {
+		report("key", nil, fmt.Sprintf("%s from %s", redactKey(c.GlobalString("fastly-key")), util.FastlyKeySource(c)))
+	}
+
+	if keyErr != nil {
+		report("api", fmt.Errorf("Skipped, as no Fastly API key is set"), "")
+	} else if who, err := util.Whoami(c.GlobalString("fastly-key")); err != nil {
+		report("api", err, "")
+	} else {
+		report("api", nil, fmt.Sprintf("authenticated as %s", who))
+	}
+
+	if c.GlobalBool("no-pager") {
+		report("pager", nil, "disabled with --no-pager or FASTLYCTL_NO_PAGER")
+	} else if pager := util.GetPager(); pager == nil {
+		report("pager", fmt.Errorf("No pager found. Set PAGER, or install less. Diffs will be printed directly"), "")
+	} else {
+		report("pager", nil, strings.Join(pager.Args, " "))
+	}
+
+	if failed > 0 {
+		return cli.NewExitError(fmt.Sprintf("%d checks failed.", failed), -1)
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/alienth/fastlyctl/fastlyapi"
+	"github.com/alienth/fastlyctl/format"
+	"github.com/alienth/fastlyctl/log"
+	"github.com/alienth/fastlyctl/util"
+	"github.com/alienth/go-fastly"
+	"github.com/urfave/cli"
+)
+
+func domainList(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+
+	serviceParam := c.Args().Get(0)
+	service, err := util.GetService(client, serviceParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	activeVersion, err := util.GetActiveVersion(service)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	domains, _, err := client.Domain.List(service.ID, activeVersion)
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Unable to list domains for service %s: %s", service.Name, err), -1)
+	}
+	if outputFormat(c) != format.Table {
+		output := make([]domainOutput, 0, len(domains))
+		for _, d := range domains {
+			output = append(output, newDomainOutput(d))
+		}
+		return printOutput(c, output)
+	}
+
+	fmt.Printf("Domains for %s:\n\n", service.Name)
+	for _, d := range domains {
+		fmt.Println(d.Name)
+	}
+	return nil
+}
+
+func domainAdd(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+
+	serviceParam := c.Args().Get(0)
+	nameParam := c.Args().Get(1)
+
+	service, err := util.GetService(client, serviceParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	version, err := draftVersion(c, client, service)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	domain := new(fastly.Domain)
+	domain.Name = nameParam
+	domain.Comment = c.String("comment")
+	if _, _, err = client.Domain.Create(service.ID, version, domain); err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error creating domain: %s", err), -1)
+	}
+	log.Info(fmt.Sprintf("Added domain %s\n", nameParam))
+
+	check, _, err := fastlyapi.New(client).Domain.Check(service.ID, version, nameParam)
+	if err != nil {
+		fmt.Printf("Unable to check DNS for domain %s: %s\n", nameParam, err)
+	} else if check.Success {
+		fmt.Printf("DNS for %s is pointed at Fastly.\n", nameParam)
+	} else {
+		fmt.Printf("DNS for %s is not yet pointed at Fastly. Currently points at: %s\n", nameParam, check.CNAME)
+	}
+
+	if err = finishDraft(c, client, service, version); err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	return nil
+}
+
+func domainRemove(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+
+	serviceParam := c.Args().Get(0)
+	nameParam := c.Args().Get(1)
+
+	service, err := util.GetService(client, serviceParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	version, err := draftVersion(c, client, service)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	if _, err = client.Domain.Delete(service.ID, version, nameParam); err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error removing domain: %s", err), -1)
+	}
+	log.Info(fmt.Sprintf("Removed domain %s\n", nameParam))
+
+	if err = finishDraft(c, client, service, version); err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	return nil
+}
+
+func domainCheck(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+
+	serviceParam := c.Args().Get(0)
+	service, err := util.GetService(client, serviceParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	activeVersion, err := util.GetActiveVersion(service)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	checks, _, err := fastlyapi.New(client).Domain.CheckAll(service.ID, activeVersion)
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Unable to check domains for service %s: %s", service.Name, err), -1)
+	}
+
+	var notReady int
+	output := make([]domainCheckOutput, 0, len(checks))
+	for _, check := range checks {
+		if !check.Success {
+			notReady++
+		}
+		output = append(output, newDomainCheckOutput(check, c.String("cname")))
+	}
+	if outputFormat(c) != format.Table {
+		if err := printOutput(c, output); err != nil {
+			return err
+		}
+	} else {
+		fmt.Printf("DNS status for domains of %s:\n\n", service.Name)
+		fmt.Printf("%-40s %-30s %-40s %s\n", "Domain", "Expected", "Actual", "Status")
+		for _, o := range output {
+			status := "ready"
+			if !o.Ready {
+				status = "NOT READY"
+			}
+			fmt.Printf("%-40s %-30s %-40s %s\n", o.Name, o.Expected, dashIfEmpty(o.Actual), status)
+		}
+	}
+
+	if notReady > 0 {
+		return cli.NewExitError(fmt.Sprintf("%d of %d domains are not pointed at Fastly.", notReady, len(checks)), -1)
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"encoding"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/alienth/fastlyctl/fastlyapi"
+	"github.com/alienth/fastlyctl/util"
+	"github.com/alienth/go-fastly"
+	"github.com/urfave/cli"
+)
+
+func serviceExport(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+
+	s, err := util.GetService(client, c.Args().Get(0))
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	version, err := util.GetActiveVersion(s)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	config, err := exportService(client, s, version, c.String("vcl-dir"))
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error exporting service %s: %s", s.Name, err), -1)
+	}
+
+	if err := writeExport(os.Stdout, s, version, config); err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	return nil
+}
+
+// writeExport writes config to w as TOML which push accepts.
+func writeExport(w io.Writer, s *fastly.Service, version uint, config SiteConfig) error {
+	// Credentials are replaced with the tokens push substitutes, so that
+	// they are not copied into the config.
+	for i := range config.S3s {
+		config.S3s[i].AccessKey = "_s3accesskey_"
+		config.S3s[i].SecretKey = "_s3secretkey_"
+	}
+
+	fmt.Fprintf(w, "# Exported from version %d of service %s (%s)\n", version, s.Name, s.ID)
+	encoder := toml.NewEncoder(w)
+	if err := encoder.Encode(map[string]interface{}{s.Name: exportValue(reflect.ValueOf(config))}); err != nil {
+		return fmt.Errorf("Error encoding config: %s", err)
+	}
+	return nil
+}
+
+// backupService writes the config and generated VCL of the active version of
+// a service to timestamped files in dir, returning the path of the config.
+func backupService(client *fastly.Client, s *fastly.Service, dir string) (string, error) {
+	version, err := util.GetActiveVersion(s)
+	if err != nil {
+		return "", err
+	}
+	config, err := exportService(client, s, version, "")
+	if err != nil {
+		return "", err
+	}
+	generated, _, err := fastlyapi.New(client).VCL.Generated(s.ID, version)
+	if err != nil {
+		return "", fmt.Errorf("Error fetching generated VCL: %s", err)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	base := filepath.Join(dir, fmt.Sprintf("%s-v%d-%s", s.Name, version, time.Now().UTC().Format("20060102T150405Z")))
+	f, err := os.Create(base + ".toml")
+	if err != nil {
+		return "", err
+	}
+	if err := writeExport(f, s, version, config); err != nil {
+		f.Close()
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+	if err := ioutil.WriteFile(base+".vcl", []byte(generated.Content), 0644); err != nil {
+		return "", err
+	}
+	return base + ".toml", nil
+}
+
+// exportService builds a SiteConfig from the objects in a version of a
+// service. If vclDir is set, VCL content is written to files in vclDir rather
+// than included in the config.
+func exportService(client *fastly.Client, s *fastly.Service, version uint, vclDir string) (SiteConfig, error) {
+	var config SiteConfig
+
+	settings, _, err := client.Settings.Get(s.ID, version)
+	if err != nil {
+		return config, fmt.Errorf("Error fetching settings: %s", err)
+	}
+	config.Settings = *settings
+
+	lists := []struct {
+		name string
+		dest interface{}
+		list func() (interface{}, error)
+	}{
+		{"domains", &config.Domains, func() (interface{}, error) {
+			l, _, err := client.Domain.List(s.ID, version)
+			return l, err
+		}},
+		{"backends", &config.Backends, func() (interface{}, error) {
+			l, _, err := client.Backend.List(s.ID, version)
+			return l, err
+		}},
+		{"conditions", &config.Conditions, func() (interface{}, error) {
+			l, _, err := client.Condition.List(s.ID, version)
+			return l, err
+		}},
+		{"cache settings", &config.CacheSettings, func() (interface{}, error) {
+			l, _, err := client.CacheSetting.List(s.ID, version)
+			return l, err
+		}},
+		{"headers", &config.Headers, func() (interface{}, error) {
+			l, _, err := client.Header.List(s.ID, version)
+			return l, err
+		}},
+		{"s3s", &config.S3s, func() (interface{}, error) {
+			l, _, err := client.S3.List(s.ID, version)
+			return l, err
+		}},
+		{"syslogs", &config.Syslogs, func() (interface{}, error) {
+			l, _, err := client.Syslog.List(s.ID, version)
+			return l, err
+		}},
+		{"gzips", &config.Gzips, func() (interface{}, error) {
+			l, _, err := client.Gzip.List(s.ID, version)
+			return l, err
+		}},
+		{"health checks", &config.HealthChecks, func() (interface{}, error) {
+			l, _, err := client.HealthCheck.List(s.ID, version)
+			return l, err
+		}},
+		{"dictionaries", &config.Dictionaries, func() (interface{}, error) {
+			l, _, err := fastlyapi.New(client).Dictionary.List(s.ID, version)
+			return l, err
+		}},
+		{"ACLs", &config.ACLs, func() (interface{}, error) {
+			l, _, err := client.ACL.List(s.ID, version)
+			return l, err
+		}},
+		{"request settings", &config.RequestSettings, func() (interface{}, error) {
+			l, _, err := client.RequestSetting.List(s.ID, version)
+			return l, err
+		}},
+		{"response objects", &config.ResponseObject, func() (interface{}, error) {
+			l, _, err := client.ResponseObject.List(s.ID, version)
+			return l, err
+		}},
+	}
+	for _, l := range lists {
+		objects, err := l.list()
+		if err != nil {
+			return config, fmt.Errorf("Error listing %s: %s", l.name, err)
+		}
+		// Each list is a slice of pointers, which is copied into the
+		// matching slice of values in config.
+		src := reflect.ValueOf(objects)
+		dest := reflect.ValueOf(l.dest).Elem()
+		for i := 0; i < src.Len(); i++ {
+			dest.Set(reflect.Append(dest, src.Index(i).Elem()))
+		}
+	}
+
+	vcls, _, err := client.VCL.List(s.ID, version)
+	if err != nil {
+		return config, fmt.Errorf("Error listing VCLs: %s", err)
+	}
+	for _, v := range vcls {
+		vcl := VCL{Name: v.Name, Main: v.Main}
+		if vclDir == "" {
+			vcl.Content = v.Content
+		} else {
+			vcl.File = filepath.Join(vclDir, v.Name+".vcl")
+			if err := ioutil.WriteFile(vcl.File, []byte(v.Content), 0644); err != nil {
+				return config, err
+			}
+		}
+		config.VCLs = append(config.VCLs, vcl)
+	}
+
+	return config, nil
+}
+
+// exportValue converts v into maps, slices and plain values for encoding,
+// omitting zero values and fields which are set by the API.
+func exportValue(v reflect.Value) interface{} {
+	if v.CanAddr() {
+		if m, ok := v.Addr().Interface().(encoding.TextMarshaler); ok && v.Kind() != reflect.Struct {
+			if text, err := m.MarshalText(); err == nil {
+				return string(text)
+			}
+		}
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		m := make(map[string]interface{})
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if field.PkgPath != "" || util.StringInSlice(field.Name, ignoredFields) || v.Field(i).IsZero() {
+				continue
+			}
+			m[field.Name] = exportValue(v.Field(i))
+		}
+		return m
+	case reflect.Slice:
+		var s []interface{}
+		for i := 0; i < v.Len(); i++ {
+			s = append(s, exportValue(v.Index(i)))
+		}
+		return s
+	case reflect.Bool:
+		return v.Bool()
+	}
+	return v.Interface()
+}
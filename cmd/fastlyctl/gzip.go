@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alienth/fastlyctl/format"
+	"github.com/alienth/fastlyctl/log"
+	"github.com/alienth/fastlyctl/util"
+	"github.com/alienth/go-fastly"
+	"github.com/urfave/cli"
+)
+
+func gzipList(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+
+	serviceParam := c.Args().Get(0)
+	service, err := util.GetService(client, serviceParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	activeVersion, err := util.GetActiveVersion(service)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	gzips, _, err := client.Gzip.List(service.ID, activeVersion)
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Unable to list gzip rules for service %s: %s", service.Name, err), -1)
+	}
+	if outputFormat(c) != format.Table {
+		output := make([]gzipOutput, 0, len(gzips))
+		for _, g := range gzips {
+			output = append(output, newGzipOutput(g))
+		}
+		return printOutput(c, output)
+	}
+
+	fmt.Printf("Gzip rules for %s:\n\n", service.Name)
+	fmt.Printf("%-30s %-50s %-30s %s\n", "Name", "Content Types", "Extensions", "Cache Condition")
+	for _, g := range gzips {
+		fmt.Printf("%-30s %-50s %-30s %s\n", g.Name, dashIfEmpty(g.ContentTypes), dashIfEmpty(g.Extensions), dashIfEmpty(g.CacheCondition))
+	}
+	return nil
+}
+
+// dashIfEmpty returns "-" in place of an empty string, to keep table columns
+// aligned.
+func dashIfEmpty(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// spaceSeparated converts a comma-separated flag value into the
+// space-separated list the API expects.
+func spaceSeparated(s string) string {
+	fields := strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' })
+	return strings.Join(fields, " ")
+}
+
+func gzipAdd(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+
+	serviceParam := c.Args().Get(0)
+	nameParam := c.Args().Get(1)
+
+	service, err := util.GetService(client, serviceParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	gzip := new(fastly.Gzip)
+	gzip.Name = nameParam
+	gzip.ContentTypes = spaceSeparated(c.String("content-types"))
+	gzip.Extensions = spaceSeparated(c.String("extensions"))
+	gzip.CacheCondition = c.String("cache-condition")
+
+	version, err := draftVersion(c, client, service)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	if _, _, err = client.Gzip.Create(service.ID, version, gzip); err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error creating gzip rule: %s", err), -1)
+	}
+	log.Info(fmt.Sprintf("Created gzip rule %s\n", nameParam))
+
+	if err = finishDraft(c, client, service, version); err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	return nil
+}
+
+func gzipRemove(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+
+	serviceParam := c.Args().Get(0)
+	nameParam := c.Args().Get(1)
+
+	service, err := util.GetService(client, serviceParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	version, err := draftVersion(c, client, service)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	if _, err = client.Gzip.Delete(service.ID, version, nameParam); err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error removing gzip rule: %s", err), -1)
+	}
+	log.Info(fmt.Sprintf("Removed gzip rule %s\n", nameParam))
+
+	if err = finishDraft(c, client, service, version); err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	return nil
+}
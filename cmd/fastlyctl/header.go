@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/alienth/fastlyctl/format"
+	"github.com/alienth/fastlyctl/log"
+	"github.com/alienth/fastlyctl/util"
+	"github.com/alienth/go-fastly"
+	"github.com/urfave/cli"
+)
+
+func headerList(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+
+	serviceParam := c.Args().Get(0)
+	service, err := util.GetService(client, serviceParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	activeVersion, err := util.GetActiveVersion(service)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	headers, _, err := client.Header.List(service.ID, activeVersion)
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Unable to list headers for service %s: %s", service.Name, err), -1)
+	}
+	if outputFormat(c) != format.Table {
+		output := make([]headerOutput, 0, len(headers))
+		for _, h := range headers {
+			output = append(output, newHeaderOutput(h))
+		}
+		return printOutput(c, output)
+	}
+
+	fmt.Printf("Headers for %s:\n\n", service.Name)
+	fmt.Printf("%-30s %-12s %-8s %-30s %-30s %8s\n", "Name", "Action", "Type", "Destination", "Source", "Priority")
+	for _, h := range headers {
+		o := newHeaderOutput(h)
+		fmt.Printf("%-30s %-12s %-8s %-30s %-30s %8d\n", o.Name, o.Action, o.Type, o.Destination, dashIfEmpty(o.Source), o.Priority)
+	}
+	return nil
+}
+
+// checkHeaderAdd is used as the Before hook for header add.
+func checkHeaderAdd(c *cli.Context) error {
+	if c.NArg() < 2 {
+		return cli.NewExitError("Please specify header name.", -1)
+	}
+	if c.String("dst") == "" {
+		return cli.NewExitError("Please specify --dst.", -1)
+	}
+	// Unknown values are left unset by UnmarshalText.
+	var action fastly.HeaderAction
+	action.UnmarshalText([]byte(c.String("action")))
+	if action == 0 {
+		return cli.NewExitError(fmt.Sprintf("Invalid header action %q. Must be one of: set, append, delete, regex, regex_repeat", c.String("action")), -1)
+	}
+	var headerType fastly.HeaderType
+	headerType.UnmarshalText([]byte(c.String("type")))
+	if headerType == 0 {
+		return cli.NewExitError(fmt.Sprintf("Invalid header type %q. Must be one of: request, fetch, cache, response", c.String("type")), -1)
+	}
+	return checkActivate(c)
+}
+
+func headerAdd(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+
+	serviceParam := c.Args().Get(0)
+	nameParam := c.Args().Get(1)
+
+	service, err := util.GetService(client, serviceParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	header := new(fastly.Header)
+	header.Name = nameParam
+	header.Action.UnmarshalText([]byte(c.String("action")))
+	header.Type.UnmarshalText([]byte(c.String("type")))
+	header.Destination = c.String("dst")
+	header.Source = c.String("src")
+	header.Regex = c.String("regex")
+	header.Substitution = c.String("substitution")
+	header.Priority = uint(c.Int("priority"))
+	header.IgnoreIfSet = fastly.Compatibool(c.Bool("ignore-if-set"))
+	header.RequestCondition = c.String("request-condition")
+	header.ResponseCondition = c.String("response-condition")
+	header.CacheCondition = c.String("cache-condition")
+
+	version, err := draftVersion(c, client, service)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	if _, _, err = client.Header.Create(service.ID, version, header); err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error creating header: %s", err), -1)
+	}
+	log.Info(fmt.Sprintf("Created header %s\n", nameParam))
+
+	if err = finishDraft(c, client, service, version); err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	return nil
+}
+
+func headerRemove(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+
+	serviceParam := c.Args().Get(0)
+	nameParam := c.Args().Get(1)
+
+	service, err := util.GetService(client, serviceParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	version, err := draftVersion(c, client, service)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	if _, err = client.Header.Delete(service.ID, version, nameParam); err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error removing header: %s", err), -1)
+	}
+	log.Info(fmt.Sprintf("Removed header %s\n", nameParam))
+
+	if err = finishDraft(c, client, service, version); err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/alienth/fastlyctl/format"
+	"github.com/alienth/fastlyctl/log"
+	"github.com/alienth/fastlyctl/util"
+	"github.com/alienth/go-fastly"
+	"github.com/urfave/cli"
+)
+
+func healthCheckList(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+
+	serviceParam := c.Args().Get(0)
+	service, err := util.GetService(client, serviceParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	activeVersion, err := util.GetActiveVersion(service)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	healthChecks, _, err := client.HealthCheck.List(service.ID, activeVersion)
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Unable to list health checks for service %s: %s", service.Name, err), -1)
+	}
+	if outputFormat(c) != format.Table {
+		output := make([]healthCheckOutput, 0, len(healthChecks))
+		for _, h := range healthChecks {
+			output = append(output, newHealthCheckOutput(h))
+		}
+		return printOutput(c, output)
+	}
+
+	fmt.Printf("Health checks for %s:\n\n", service.Name)
+	fmt.Printf("%-30s %-6s %-30s %-30s %8s %9s %6s %8s\n", "Name", "Method", "Host", "Path", "Expected", "Interval", "Window", "Threshold")
+	for _, h := range healthChecks {
+		fmt.Printf("%-30s %-6s %-30s %-30s %8d %9d %6d %8d\n", h.Name, h.Method, h.Host, h.Path, h.ExpectedResponse, h.CheckInterval, h.Window, h.Threshold)
+	}
+	return nil
+}
+
+// applyHealthCheckFlags sets fields on h for each health check flag which was
+// given.
+func applyHealthCheckFlags(c *cli.Context, h *fastly.HealthCheck) {
+	if c.IsSet("host") {
+		h.Host = c.String("host")
+	}
+	if c.IsSet("path") {
+		h.Path = c.String("path")
+	}
+	if c.IsSet("method") {
+		h.Method = c.String("method")
+	}
+	if c.IsSet("http-version") {
+		h.HTTPVersion = c.String("http-version")
+	}
+	if c.IsSet("expected-response") {
+		h.ExpectedResponse = uint(c.Int("expected-response"))
+	}
+	if c.IsSet("check-interval") {
+		h.CheckInterval = uint(c.Int("check-interval"))
+	}
+	if c.IsSet("check-timeout") {
+		h.Timeout = uint(c.Int("check-timeout"))
+	}
+	if c.IsSet("threshold") {
+		h.Threshold = uint(c.Int("threshold"))
+	}
+	if c.IsSet("window") {
+		h.Window = uint(c.Int("window"))
+	}
+	if c.IsSet("initial") {
+		h.Initial = uint(c.Int("initial"))
+	}
+	if c.IsSet("comment") {
+		h.Comment = c.String("comment")
+	}
+}
+
+func healthCheckAdd(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+
+	serviceParam := c.Args().Get(0)
+	nameParam := c.Args().Get(1)
+
+	service, err := util.GetService(client, serviceParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	healthCheck := new(fastly.HealthCheck)
+	healthCheck.Name = nameParam
+	applyHealthCheckFlags(c, healthCheck)
+
+	version, err := draftVersion(c, client, service)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	if _, _, err = client.HealthCheck.Create(service.ID, version, healthCheck); err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error creating health check: %s", err), -1)
+	}
+	log.Info(fmt.Sprintf("Created health check %s\n", nameParam))
+
+	if err = finishDraft(c, client, service, version); err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	return nil
+}
+
+func healthCheckUpdate(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+
+	serviceParam := c.Args().Get(0)
+	nameParam := c.Args().Get(1)
+
+	service, err := util.GetService(client, serviceParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	version, err := draftVersion(c, client, service)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	healthCheck, _, err := client.HealthCheck.Get(service.ID, version, nameParam)
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error fetching health check %s: %s", nameParam, err), -1)
+	}
+	// Zero out read-only fields
+	healthCheck.ServiceID = ""
+	healthCheck.Version = 0
+	applyHealthCheckFlags(c, healthCheck)
+
+	if _, _, err = client.HealthCheck.Update(service.ID, version, nameParam, healthCheck); err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error updating health check: %s", err), -1)
+	}
+	log.Info(fmt.Sprintf("Updated health check %s\n", nameParam))
+
+	if err = finishDraft(c, client, service, version); err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	return nil
+}
+
+func healthCheckRemove(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+
+	serviceParam := c.Args().Get(0)
+	nameParam := c.Args().Get(1)
+
+	service, err := util.GetService(client, serviceParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	version, err := draftVersion(c, client, service)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	if _, err = client.HealthCheck.Delete(service.ID, version, nameParam); err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error removing health check: %s", err), -1)
+	}
+	log.Info(fmt.Sprintf("Removed health check %s\n", nameParam))
+
+	if err = finishDraft(c, client, service, version); err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+)
+
+// envReference matches ${VAR} and ${VAR:-default} references in config
+// values.
+var envReference = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// interpolateEnv replaces environment variable references in s. An error is
+// returned if a referenced variable is unset and no default was given.
+func interpolateEnv(s string) (string, error) {
+	var err error
+	result := envReference.ReplaceAllStringFunc(s, func(ref string) string {
+		match := envReference.FindStringSubmatch(ref)
+		if value, ok := os.LookupEnv(match[1]); ok {
+			return value
+		}
+		if match[2] != "" {
+			return match[3]
+		}
+		if err == nil {
+			err = fmt.Errorf("environment variable %s is not set", match[1])
+		}
+		return ref
+	})
+	return result, err
+}
+
+// interpolateConfig replaces environment variable references in every string
+// field of config.
+func interpolateConfig(config *SiteConfig) error {
+	return interpolateValue(reflect.ValueOf(config).Elem())
+}
+
+func interpolateValue(v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.String:
+		if !v.CanSet() {
+			return nil
+		}
+		s, err := interpolateEnv(v.String())
+		if err != nil {
+			return err
+		}
+		v.SetString(s)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if err := interpolateValue(v.Field(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			if err := interpolateValue(v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Ptr:
+		if !v.IsNil() {
+			return interpolateValue(v.Elem())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/alienth/fastlyctl/fastlyapi"
+	"github.com/alienth/fastlyctl/format"
+	"github.com/alienth/fastlyctl/log"
+	"github.com/alienth/fastlyctl/util"
+	"github.com/alienth/go-fastly"
+	"github.com/urfave/cli"
+)
+
+// loggingTypes lists the logging endpoint types which can be managed.
+var loggingTypes = []string{"s3", "syslog", "gcs"}
+
+func loggingList(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+
+	serviceParam := c.Args().Get(0)
+	service, err := util.GetService(client, serviceParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	activeVersion, err := util.GetActiveVersion(service)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	var output []loggingOutput
+	s3s, _, err := client.S3.List(service.ID, activeVersion)
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Unable to list S3 logging endpoints for service %s: %s", service.Name, err), -1)
+	}
+	for _, s3 := range s3s {
+		output = append(output, loggingOutput{Type: "s3", Name: s3.Name, Destination: s3.BucketName + s3.Path})
+	}
+	syslogs, _, err := client.Syslog.List(service.ID, activeVersion)
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Unable to list syslog logging endpoints for service %s: %s", service.Name, err), -1)
+	}
+	for _, syslog := range syslogs {
+		output = append(output, loggingOutput{Type: "syslog", Name: syslog.Name, Destination: syslog.Address + ":" + strconv.Itoa(int(syslog.Port))})
+	}
+	gcss, _, err := fastlyapi.New(client).GCS.List(service.ID, activeVersion)
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Unable to list GCS logging endpoints for service %s: %s", service.Name, err), -1)
+	}
+	for _, gcs := range gcss {
+		output = append(output, loggingOutput{Type: "gcs", Name: gcs.Name, Destination: gcs.BucketName + gcs.Path})
+	}
+
+	if outputFormat(c) != format.Table {
+		if output == nil {
+			output = []loggingOutput{}
+		}
+		return printOutput(c, output)
+	}
+
+	fmt.Printf("Logging endpoints for %s:\n\n", service.Name)
+	fmt.Printf("%-8s %-30s %s\n", "Type", "Name", "Destination")
+	for _, l := range output {
+		fmt.Printf("%-8s %-30s %s\n", l.Type, l.Name, l.Destination)
+	}
+	return nil
+}
+
+func loggingAddS3(c *cli.Context) error {
+	s3 := &fastly.S3{
+		Name:              c.Args().Get(1),
+		BucketName:        c.String("bucket"),
+		Domain:            c.String("domain"),
+		AccessKey:         c.String("access-key"),
+		SecretKey:         c.String("secret-key"),
+		Path:              c.String("path"),
+		Period:            uint(c.Int("period")),
+		GzipLevel:         uint(c.Int("gzip-level")),
+		Format:            c.String("format"),
+		ResponseCondition: c.String("response-condition"),
+		TimestampFormat:   c.String("timestamp-format"),
+	}
+	if s3.TimestampFormat == "" {
+		s3.TimestampFormat = defaultS3TimestampFormat
+	}
+	return loggingAdd(c, "S3", func(client *fastly.Client, service *fastly.Service, version uint) error {
+		_, _, err := client.S3.Create(service.ID, version, s3)
+		return err
+	})
+}
+
+func loggingAddSyslog(c *cli.Context) error {
+	syslog := &fastly.Syslog{
+		Name:              c.Args().Get(1),
+		Address:           c.String("address"),
+		Port:              uint(c.Int("port")),
+		UseTLS:            fastly.Compatibool(c.Bool("use-tls")),
+		TLSCACert:         c.String("tls-ca-cert"),
+		TLSHostname:       c.String("tls-hostname"),
+		Token:             c.String("token"),
+		Format:            c.String("format"),
+		ResponseCondition: c.String("response-condition"),
+	}
+	return loggingAdd(c, "syslog", func(client *fastly.Client, service *fastly.Service, version uint) error {
+		_, _, err := client.Syslog.Create(service.ID, version, syslog)
+		return err
+	})
+}
+
+func loggingAddGCS(c *cli.Context) error {
+	gcs := &fastlyapi.GCS{
+		Name:              c.Args().Get(1),
+		BucketName:        c.String("bucket"),
+		User:              c.String("user"),
+		SecretKey:         c.String("secret-key"),
+		Path:              c.String("path"),
+		Period:            uint(c.Int("period")),
+		GzipLevel:         uint(c.Int("gzip-level")),
+		Format:            c.String("format"),
+		ResponseCondition: c.String("response-condition"),
+		TimestampFormat:   c.String("timestamp-format"),
+	}
+	return loggingAdd(c, "GCS", func(client *fastly.Client, service *fastly.Service, version uint) error {
+		_, _, err := fastlyapi.New(client).GCS.Create(service.ID, version, gcs)
+		return err
+	})
+}
+
+// loggingAdd creates a logging endpoint on a draft version using create.
+func loggingAdd(c *cli.Context, kind string, create func(*fastly.Client, *fastly.Service, uint) error) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+
+	serviceParam := c.Args().Get(0)
+	nameParam := c.Args().Get(1)
+
+	service, err := util.GetService(client, serviceParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	version, err := draftVersion(c, client, service)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	if err = create(client, service, version); err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error creating %s logging endpoint: %s", kind, err), -1)
+	}
+	log.Info(fmt.Sprintf("Created %s logging endpoint %s\n", kind, nameParam))
+
+	if err = finishDraft(c, client, service, version); err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	return nil
+}
+
+func loggingRemove(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+
+	serviceParam := c.Args().Get(0)
+	typeParam := c.Args().Get(1)
+	nameParam := c.Args().Get(2)
+
+	service, err := util.GetService(client, serviceParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	version, err := draftVersion(c, client, service)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	switch typeParam {
+	case "s3":
+		_, err = client.S3.Delete(service.ID, version, nameParam)
+	case "syslog":
+		_, err = client.Syslog.Delete(service.ID, version, nameParam)
+	case "gcs":
+		_, err = fastlyapi.New(client).GCS.Delete(service.ID, version, nameParam)
+	}
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error removing logging endpoint: %s", err), -1)
+	}
+	log.Info(fmt.Sprintf("Removed %s logging endpoint %s\n", typeParam, nameParam))
+
+	if err = finishDraft(c, client, service, version); err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	return nil
+}
@@ -2,14 +2,237 @@ package main
 
 import (
 	"fmt"
+	"net/http"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/alienth/fastlyctl/fastlyapi"
+	"github.com/alienth/fastlyctl/format"
 	"github.com/alienth/fastlyctl/log"
 	"github.com/alienth/fastlyctl/util"
 	"github.com/urfave/cli"
 )
 
+// draftFlags are used by commands which make changes on a draft version.
+var draftFlags = []cli.Flag{
+	cli.IntFlag{
+		Name:  "version",
+		Usage: "Make changes on an existing draft `VERSION` rather than cloning the active version.",
+	},
+	cli.BoolFlag{
+		Name:  "activate",
+		Usage: "Validate and activate the draft version once changes have been made.",
+	},
+	cli.BoolFlag{
+		Name:  "ignore-drafts",
+		Usage: "Do not warn about other unactivated draft versions when activating.",
+	},
+}
+
+// waitFlags are shared by the commands which activate versions.
+var waitFlags = []cli.Flag{
+	cli.BoolFlag{
+		Name:  "wait",
+		Usage: "After activating, poll until Fastly reports the new version as active.",
+	},
+	cli.DurationFlag{
+		Name:  "wait-timeout",
+		Value: 5 * time.Minute,
+		Usage: "Give up waiting for activation after `DURATION` and exit non-zero.",
+	},
+}
+
+// dictionaryWriteFlags are shared by the commands which write many dictionary
+// items at once.
+var dictionaryWriteFlags = []cli.Flag{
+	cli.IntFlag{
+		Name:  "concurrency, parallel",
+		Value: 4,
+		Usage: "Number of batch requests to send at once.",
+	},
+}
+
+var backendFlags = []cli.Flag{
+	cli.IntFlag{
+		Name:  "port",
+		Usage: "`PORT` to connect to on the backend.",
+	},
+	cli.BoolFlag{
+		Name:  "use-ssl",
+		Usage: "Connect to the backend using TLS.",
+	},
+	cli.StringFlag{
+		Name:  "ssl-cert-hostname",
+		Usage: "`HOSTNAME` to verify the backend's certificate against.",
+	},
+	cli.IntFlag{
+		Name:  "connect-timeout",
+		Usage: "Connection timeout in `MILLISECONDS`.",
+	},
+}
+
+var healthCheckFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "host",
+		Usage: "`HOST` header to send with the check.",
+	},
+	cli.StringFlag{
+		Name:  "path",
+		Usage: "`PATH` to request.",
+	},
+	cli.StringFlag{
+		Name:  "method",
+		Usage: "HTTP `METHOD` to use, such as HEAD or GET.",
+	},
+	cli.StringFlag{
+		Name:  "http-version",
+		Usage: "HTTP `VERSION` to use.",
+	},
+	cli.IntFlag{
+		Name:  "expected-response",
+		Usage: "HTTP `STATUS` a healthy backend responds with.",
+	},
+	cli.IntFlag{
+		Name:  "check-interval",
+		Usage: "Time between checks in `MILLISECONDS`.",
+	},
+	cli.IntFlag{
+		Name:  "check-timeout",
+		Usage: "Time to wait for a response in `MILLISECONDS`.",
+	},
+	cli.IntFlag{
+		Name:  "threshold",
+		Usage: "`COUNT` of checks in the window which must pass for the backend to be healthy.",
+	},
+	cli.IntFlag{
+		Name:  "window",
+		Usage: "`COUNT` of most recent checks to consider.",
+	},
+	cli.IntFlag{
+		Name:  "initial",
+		Usage: "`COUNT` of checks assumed to pass when the check is first loaded.",
+	},
+	cli.StringFlag{
+		Name:  "comment",
+		Usage: "Optional comment to attach to the health check.",
+	},
+}
+
+var requestSettingFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "action",
+		Usage: "`ACTION` to take on matching requests. One of: lookup, pass, or empty for none.",
+	},
+	cli.StringFlag{
+		Name:  "xff",
+		Usage: "How to handle the X-Forwarded-For header. One of: clear, leave, append, append_all, overwrite.",
+	},
+	cli.BoolFlag{
+		Name:  "force-miss",
+		Usage: "Force a cache miss for matching requests.",
+	},
+	cli.BoolFlag{
+		Name:  "force-ssl",
+		Usage: "Redirect matching requests over plain HTTP to HTTPS.",
+	},
+	cli.BoolFlag{
+		Name:  "timer-support",
+		Usage: "Inject the X-Timer header into requests.",
+	},
+	cli.BoolFlag{
+		Name:  "bypass-busy-wait",
+		Usage: "Disable request collapsing for matching requests.",
+	},
+	cli.BoolFlag{
+		Name:  "geo-headers",
+		Usage: "Inject Fastly-Geo headers into requests.",
+	},
+	cli.IntFlag{
+		Name:  "max-stale-age",
+		Usage: "Maximum `SECONDS` to serve stale content while revalidating.",
+	},
+	cli.StringFlag{
+		Name:  "default-host",
+		Usage: "`HOST` header to use if the request has none.",
+	},
+	cli.StringFlag{
+		Name:  "hash-keys",
+		Usage: "Comma-separated list of VCL variables to use in the cache hash, replacing the defaults.",
+	},
+	cli.StringFlag{
+		Name:  "request-condition",
+		Usage: "Only apply the setting to requests matching condition `NAME`.",
+	},
+}
+
+var cacheSettingFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "action",
+		Usage: "`ACTION` to take on matching responses. One of: pass, cache, restart.",
+	},
+	cli.IntFlag{
+		Name:  "ttl",
+		Usage: "Time to cache matching responses for, in `SECONDS`.",
+	},
+	cli.IntFlag{
+		Name:  "stale-ttl",
+		Usage: "Time to serve stale content for while revalidating, in `SECONDS`.",
+	},
+	cli.StringFlag{
+		Name:  "cache-condition",
+		Usage: "Only apply the setting when cache condition `NAME` matches.",
+	},
+}
+
+// fileLoggingFlags are shared by logging endpoints which write log files.
+var fileLoggingFlags = append([]cli.Flag{
+	cli.StringFlag{
+		Name:  "path",
+		Usage: "`PATH` to write log files under.",
+	},
+	cli.IntFlag{
+		Name:  "period",
+		Value: 3600,
+		Usage: "How often to write a new log file, in `SECONDS`.",
+	},
+	cli.IntFlag{
+		Name:  "gzip-level",
+		Usage: "gzip compression `LEVEL` for log files. 0 disables compression.",
+	},
+	cli.StringFlag{
+		Name:  "format",
+		Usage: "Apache style log `FORMAT`.",
+	},
+	cli.StringFlag{
+		Name:  "response-condition",
+		Usage: "Only log responses matching the `CONDITION`.",
+	},
+}, draftFlags...)
+
+// checkLoggingAdd returns a Before function for logging add commands which
+// requires an endpoint name and the given flag.
+func checkLoggingAdd(required string) func(c *cli.Context) error {
+	return func(c *cli.Context) error {
+		if c.NArg() < 2 {
+			return cli.NewExitError("Please specify logging endpoint name.", -1)
+		}
+		if c.String(required) == "" {
+			return cli.NewExitError(fmt.Sprintf("Please specify --%s.", required), -1)
+		}
+		return checkActivate(c)
+	}
+}
+
+// checkActivate ensures prompting will be possible if --activate was given.
+func checkActivate(c *cli.Context) error {
+	if c.Bool("activate") && !util.IsInteractive() && !c.GlobalBool("assume-yes") {
+		return cli.NewExitError(util.ErrNonInteractive.Error(), -1)
+	}
+	return nil
+}
+
 func main() {
 	app := cli.NewApp()
 	app.Name = "fastlyctl"
@@ -18,27 +241,154 @@ func main() {
 		cli.StringFlag{
 			Name:  "config, c",
 			Value: "config.toml",
-			Usage: "Load Fastly configuration from `FILE`",
+			Usage: "Load Fastly configuration from `FILE`, or from stdin if - is given. If unset, searched for in CWD, $XDG_CONFIG_HOME/fastlyctl, and ~/.config/fastlyctl",
+		},
+		cli.BoolFlag{
+			Name:  "lax",
+			Usage: "Warn about unknown keys in the config, rather than failing.",
+		},
+		cli.StringFlag{
+			Name:  "config-format",
+			Value: "toml",
+			Usage: "`FORMAT` of a config read from stdin. One of: toml, yaml, json.",
 		},
 		cli.StringFlag{
 			Name:   "fastly-key, K",
-			Usage:  "Fastly API Key. Can be read from 'fastly_key' file in CWD.",
-			EnvVar: "FASTLY_KEY",
+			Usage:  "Fastly API Key. If unset, read from FASTLY_KEY, then FASTLY_API_TOKEN, then the 'fastly_key' file in CWD.",
+			EnvVar: util.FastlyKeyEnvVars,
 			Value:  util.GetFastlyKey(),
 		},
+		cli.StringFlag{
+			Name:  "profile, p",
+			Usage: "Use the Fastly API key from `PROFILE` in the profiles table of the config file.",
+		},
 		cli.BoolFlag{
 			Name:  "debug, d",
 			Usage: "Print more detailed info for debugging.",
 		},
+		cli.BoolFlag{
+			Name:  "dry-run",
+			Usage: "Print the changes a command would make, without making them. Read-only API calls are still made.",
+		},
+		cli.BoolFlag{
+			Name:  "check-auth",
+			Usage: "Confirm the Fastly API key is valid before running the command. Enabled by --debug unless set to false with --check-auth=false.",
+		},
+		cli.BoolFlag{
+			Name:  "quiet, q",
+			Usage: "Suppress informational output. Command output, warnings and errors are still printed.",
+		},
 		cli.BoolFlag{
 			Name:  "assume-yes, y",
 			Usage: "Assume 'yes' to all prompts. USE ONLY IF YOU ARE CERTAIN YOUR COMMANDS WON'T BREAK ANYTHING!",
 		},
+		cli.StringFlag{
+			Name:  "output, o",
+			Value: "table",
+			Usage: "Output `FORMAT` for list commands and push results. One of: table, json, yaml, go-template=TEMPLATE.",
+		},
+		cli.StringFlag{
+			Name:  "output-template",
+			Usage: "Render each item of list output through the Go text/template `TEMPLATE`, such as '{{.Name}} {{.ID}}'. The fields available are listed in the help of each command.",
+		},
+		cli.IntFlag{
+			Name:  "diff-context",
+			Value: 3,
+			Usage: "Number of context `LINES` to show around changes in diffs.",
+		},
+		cli.BoolFlag{
+			Name:   "no-pager",
+			Usage:  "Print diffs and other long output directly to stdout rather than through a pager.",
+			EnvVar: "FASTLYCTL_NO_PAGER",
+		},
+		cli.BoolFlag{
+			Name:  "no-color",
+			Usage: "Disable colorized output. Color is also disabled if NO_COLOR is set.",
+		},
+		cli.IntFlag{
+			Name:  "max-retries",
+			Value: 3,
+			Usage: "Number of times to retry API calls which fail due to rate limiting or server errors.",
+		},
+		cli.DurationFlag{
+			Name:  "timeout",
+			Value: 60 * time.Second,
+			Usage: "Time limit for each individual API request. 0 disables the limit.",
+		},
+		cli.StringFlag{
+			Name:   "api-url",
+			Usage:  "Send API requests to the host in `URL` rather than api.fastly.com, such as a mock server in tests.",
+			EnvVar: "FASTLY_API_URL",
+		},
+		cli.StringFlag{
+			Name:  "ca-cert",
+			Usage: "Trust the PEM encoded CA certificates in `FILE` when connecting to the Fastly API, in addition to the system CAs.",
+		},
+		cli.BoolFlag{
+			Name:  "insecure",
+			Usage: "Do not verify the TLS certificate of the Fastly API. Dangerous; prefer --ca-cert.",
+		},
+		cli.DurationFlag{
+			Name:  "prompt-timeout",
+			Usage: "Choose the default answer to activation prompts if no answer is given within this time. 0 waits forever.",
+		},
 	}
 
 	app.Before = func(c *cli.Context) error {
-		if err := util.CheckFastlyKey(c); err != nil {
-			return err
+		if err := util.SetStdinFormat(c.GlobalString("config-format")); err != nil {
+			return cli.NewExitError(err.Error(), -1)
+		}
+		if apiURL := c.GlobalString("api-url"); apiURL != "" {
+			if err := util.SetAPIURL(apiURL); err != nil {
+				return cli.NewExitError(err.Error(), -1)
+			}
+		}
+		// doctor reports on the API key itself, and config fmt only
+		// reads local files, so both run without one.
+		isDoctor := c.Args().First() == "doctor"
+		isOffline := isDoctor || (c.Args().First() == "config" && c.Args().Get(1) == "fmt")
+		if !isOffline {
+			if err := util.CheckFastlyKey(c); err != nil {
+				return err
+			}
+		}
+		var transport http.RoundTripper = http.DefaultTransport
+		if c.GlobalIsSet("ca-cert") || c.GlobalBool("insecure") {
+			t, err := util.NewTransport(c.GlobalString("ca-cert"), c.GlobalBool("insecure"))
+			if err != nil {
+				return cli.NewExitError(err.Error(), -1)
+			}
+			if c.GlobalBool("insecure") {
+				fmt.Fprintf(os.Stderr, "WARNING: TLS certificate verification is disabled. Responses from the Fastly API could be forged.\n")
+			}
+			transport = t
+		}
+		if c.GlobalString("api-url") != "" {
+			transport = &util.APIURLTransport{Base: transport}
+		}
+		if c.GlobalBool("debug") {
+			log.EnableDebug()
+			transport = &log.Transport{Base: transport}
+		}
+		if c.GlobalBool("quiet") {
+			log.EnableQuiet()
+		}
+		if c.GlobalBool("dry-run") {
+			util.SetDryRun(true)
+			transport = &util.DryRunTransport{Base: transport}
+		}
+		transport = &fastlyapi.ErrorTransport{Base: transport}
+		http.DefaultClient.Transport = transport
+		util.SetMaxRetries(c.GlobalInt("max-retries"))
+		util.SetTimeout(c.GlobalDuration("timeout"))
+		util.SetPromptTimeout(c.GlobalDuration("prompt-timeout"))
+		util.SetDiffContext(c.GlobalInt("diff-context"))
+		util.SetColor(util.ColorAllowed(c.GlobalBool("no-color")))
+		util.SetPager(!c.GlobalBool("no-pager"))
+		if !isOffline && (c.GlobalBool("check-auth") || (c.GlobalBool("debug") && !c.GlobalIsSet("check-auth"))) {
+			if err := util.CheckAuth(c); err != nil {
+				return err
+			}
 		}
 		return nil
 	}
@@ -49,7 +399,7 @@ func main() {
 			Aliases:   []string{"p"},
 			Usage:     "Push locally defined service configuration options to Fastly.",
 			ArgsUsage: "<SERVICE_NAME>...",
-			Flags: []cli.Flag{
+			Flags: append([]cli.Flag{
 				cli.BoolFlag{
 					Name:  "all, a",
 					Usage: "Push all services listed in config file",
@@ -58,24 +408,128 @@ func main() {
 					Name:  "noop, n",
 					Usage: "Push new config versions, but do not activate.",
 				},
-			},
+				cli.BoolFlag{
+					Name:  "force, f",
+					Usage: "Activate new versions even if they are identical to the active version, or have validation warnings when --fail-on-warnings is set.",
+				},
+				cli.BoolFlag{
+					Name:  "fail-on-warnings",
+					Usage: "Do not activate versions which validate with warnings.",
+				},
+				cli.StringFlag{
+					Name:  "diff-output",
+					Usage: "Write the diff of each activated version to `FILE`.",
+				},
+				cli.IntFlag{
+					Name:  "concurrency",
+					Value: 4,
+					Usage: "Number of services to push at once. Only applies when used with --assume-yes, --noop, or --diff-only.",
+				},
+				cli.StringSliceFlag{
+					Name:  "only",
+					Usage: "Only push services whose names match the glob `PATTERN`. May be repeated.",
+				},
+				cli.StringSliceFlag{
+					Name:  "skip",
+					Usage: "Do not push services whose names match the glob `PATTERN`. May be repeated, and takes precedence over --only.",
+				},
+				cli.BoolFlag{
+					Name:  "diff-only",
+					Usage: "Prepare new config versions and show their diffs against the active versions, but do not activate.",
+				},
+				cli.BoolFlag{
+					Name:  "validate-only",
+					Usage: "Check the config file and report any problems without making changes.",
+				},
+				cli.BoolFlag{
+					Name:  "summary",
+					Usage: "Only print the number of additions and removals for each service, rather than the full diff.",
+				},
+				cli.BoolFlag{
+					Name:  "ignore-drafts",
+					Usage: "Do not warn about unactivated draft versions newer than the active version.",
+				},
+				cli.StringFlag{
+					Name:  "comment",
+					Usage: "Set `COMMENT` on each new version before it is activated, such as a ticket number.",
+				},
+				cli.StringFlag{
+					Name:  "backup",
+					Usage: "Before activating, save the config and generated VCL of each active version to timestamped files in `DIR`.",
+				},
+				cli.IntFlag{
+					Name:  "from-version",
+					Usage: "Clone the new version from `VERSION` rather than the active version. Only one service may be pushed.",
+				},
+				cli.BoolFlag{
+					Name:  "force-clone",
+					Usage: "Always clone a fresh version, rather than reusing a draft left by an earlier push.",
+				},
+			}, waitFlags...),
 			Before: func(c *cli.Context) error {
-				if !util.IsInteractive() && !c.GlobalBool("assume-yes") {
+				if err := checkOutputFormat(c); err != nil {
+					return err
+				}
+				// Keep stdout parseable when a structured summary of the
+				// push has been requested.
+				if outputFormat(c) != format.Table {
+					log.EnableQuiet()
+				}
+				if !util.IsInteractive() && !c.GlobalBool("assume-yes") && !c.GlobalBool("dry-run") && !c.Bool("validate-only") && !c.Bool("diff-only") {
 					return cli.NewExitError(util.ErrNonInteractive.Error(), -1)
 				}
 				if (!c.Bool("all") && !c.Args().Present()) || (c.Bool("all") && c.Args().Present()) {
 					return cli.NewExitError("Error: either specify service names to be pushed, or push all with -a", -1)
 				}
-				if c.GlobalBool("debug") {
-					log.EnableDebug()
+				if c.IsSet("concurrency") && c.Int("concurrency") > 1 && !c.GlobalBool("assume-yes") && !c.Bool("noop") && !c.Bool("diff-only") {
+					return cli.NewExitError("Error: --concurrency requires one of --assume-yes, --noop, or --diff-only", -1)
+				}
+				if c.IsSet("from-version") && (c.Int("from-version") < 1 || c.Bool("all") || c.NArg() != 1) {
+					return cli.NewExitError("Error: --from-version requires a single service and a positive version number", -1)
+				}
+				if c.Bool("validate-only") {
+					return nil
 				}
-				if c.Bool("noop") {
-					fmt.Printf("!!! Running in no-op mode. Changes will be prepared, but not activated.\n\n")
+				if err := util.ResetDiffOutput(c); err != nil {
+					return cli.NewExitError(err.Error(), -1)
+				}
+				if c.Bool("noop") || c.Bool("diff-only") {
+					log.Info("!!! Running in no-op mode. Changes will be prepared, but not activated.\n\n")
 				}
 				return nil
 			},
 			Action: syncConfig,
 		},
+		cli.Command{
+			Name:  "config",
+			Usage: "Inspect the local service configuration.",
+			Subcommands: cli.Commands{
+				cli.Command{
+					Name:      "diff",
+					Usage:     "Compare the local config for a service against its active version",
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>)",
+					Action:    configDiff,
+					Before: func(c *cli.Context) error {
+						if !c.Args().Present() {
+							return cli.NewExitError("Please specify service.", -1)
+						}
+						return nil
+					},
+				},
+				cli.Command{
+					Name:      "fmt",
+					Usage:     "Rewrite TOML config files in canonical form, with tables and keys sorted. Defaults to the config file in use",
+					ArgsUsage: "[<FILE>...]",
+					Action:    configFmt,
+					Flags: []cli.Flag{
+						cli.BoolFlag{
+							Name:  "check",
+							Usage: "Print the files which are not in canonical form and exit non-zero, without rewriting them.",
+						},
+					},
+				},
+			},
+		},
 		cli.Command{
 			Name:    "version",
 			Aliases: []string{"v"},
@@ -92,15 +546,36 @@ func main() {
 					Name:      "list",
 					Usage:     "List versions associated with a given service",
 					Action:    versionList,
-					ArgsUsage: "<SERVICE_NAME>",
+					Before:    checkOutputFormat,
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>)",
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "since",
+							Usage: "Only list versions updated at or after `TIME`, given as RFC3339 or a duration ago such as 7d or 12h.",
+						},
+						cli.StringFlag{
+							Name:  "until",
+							Usage: "Only list versions updated at or before `TIME`, given as RFC3339 or a duration ago such as 7d or 12h.",
+						},
+						cli.IntFlag{
+							Name:  "limit",
+							Usage: "Only list the most recent `N` versions.",
+						},
+					},
 				},
 				cli.Command{
 					Name:      "validate",
-					Usage:     "Validate a specified VERSION",
-					ArgsUsage: "<SERVICE_NAME> <VERSION>",
+					Usage:     "Validate a specified VERSION, or the latest draft if VERSION is latest",
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>) (<VERSION> | latest)",
 					Action:    versionValidate,
+					Flags: []cli.Flag{
+						cli.BoolFlag{
+							Name:  "strict",
+							Usage: "Exit non-zero if the version validates with warnings.",
+						},
+					},
 					Before: func(c *cli.Context) error {
-						if _, err := strconv.Atoi(c.Args().Get(1)); err != nil {
+						if _, err := strconv.Atoi(c.Args().Get(1)); err != nil && c.Args().Get(1) != "latest" {
 							return cli.NewExitError("Please specify version to validate.", -1)
 						}
 						return nil
@@ -108,17 +583,126 @@ func main() {
 				},
 				cli.Command{
 					Name:      "activate",
-					Usage:     "Activate a specified VERSION",
-					ArgsUsage: "<SERVICE_NAME> <VERSION>",
+					Usage:     "Activate a specified VERSION. A VERSION of latest activates the latest draft, after showing its diff and asking for confirmation",
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>) (<VERSION> | latest)",
 					Action:    versionActivate,
+					Flags: append([]cli.Flag{
+						cli.BoolFlag{
+							Name:  "force, f",
+							Usage: "Activate VERSION even if it is identical to the active version, is a latest draft older than the active version, or has validation warnings when --fail-on-warnings is set.",
+						},
+						cli.BoolFlag{
+							Name:  "fail-on-warnings",
+							Usage: "Validate VERSION first, and do not activate it if it has validation warnings.",
+						},
+						cli.StringFlag{
+							Name:  "diff-output",
+							Usage: "Write the diff from the active version to VERSION to `FILE`.",
+						},
+						cli.BoolFlag{
+							Name:  "summary",
+							Usage: "Print the number of additions and removals from the active version to VERSION before activating.",
+						},
+					}, waitFlags...),
 					Before: func(c *cli.Context) error {
 						if !util.IsInteractive() && !c.GlobalBool("assume-yes") {
 							return cli.NewExitError(util.ErrNonInteractive.Error(), -1)
 						}
-						if _, err := strconv.Atoi(c.Args().Get(1)); err != nil {
+						if _, err := strconv.Atoi(c.Args().Get(1)); err != nil && c.Args().Get(1) != "latest" {
 							return cli.NewExitError("Please specify version to activate.", -1)
 						}
-						return versionValidate(c)
+						if err := util.ResetDiffOutput(c); err != nil {
+							return cli.NewExitError(err.Error(), -1)
+						}
+						return nil
+					},
+				},
+				cli.Command{
+					Name:      "diff",
+					Usage:     "Show the diff between two versions. FROM defaults to the active version",
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>) [<FROM>] <TO>",
+					Action:    versionDiff,
+					Flags: []cli.Flag{
+						cli.BoolFlag{
+							Name:  "no-pager",
+							Usage: "Print the diff directly to stdout rather than through a pager.",
+						},
+						cli.StringFlag{
+							Name:  "format",
+							Value: "text",
+							Usage: "`FORMAT` of the diff. One of: text, html, html_simple, json. Formats other than text are printed as Fastly returns them.",
+						},
+					},
+					Before: func(c *cli.Context) error {
+						if _, err := strconv.Atoi(c.Args().Get(1)); err != nil {
+							return cli.NewExitError("Please specify version to diff.", -1)
+						}
+						switch c.String("format") {
+						case "text", "html", "html_simple", "json":
+						default:
+							return cli.NewExitError(fmt.Sprintf("Unknown diff format %q.", c.String("format")), -1)
+						}
+						return nil
+					},
+				},
+				cli.Command{
+					Name:      "rollback",
+					Usage:     "Re-activate the version which was active prior to the current one, or VERSION if given",
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>) [<VERSION>]",
+					Action:    versionRollback,
+					Flags: []cli.Flag{
+						cli.BoolFlag{
+							Name:  "ignore-drafts",
+							Usage: "Do not warn about unactivated draft versions newer than the active version.",
+						},
+					},
+					Before: func(c *cli.Context) error {
+						if !util.IsInteractive() && !c.GlobalBool("assume-yes") {
+							return cli.NewExitError(util.ErrNonInteractive.Error(), -1)
+						}
+						return nil
+					},
+				},
+				cli.Command{
+					Name:      "clone",
+					Usage:     "Clone a VERSION into a new draft version. VERSION defaults to the active version",
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>) [<VERSION>]",
+					Action:    versionClone,
+				},
+				cli.Command{
+					Name:      "deactivate",
+					Usage:     "Deactivate the active version of a service",
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>)",
+					Action:    versionDeactivate,
+					Before: func(c *cli.Context) error {
+						if !util.IsInteractive() && !c.GlobalBool("assume-yes") {
+							return cli.NewExitError(util.ErrNonInteractive.Error(), -1)
+						}
+						return nil
+					},
+				},
+				cli.Command{
+					Name:      "comment",
+					Usage:     "Print the comment on a VERSION, or replace it with COMMENT",
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>) <VERSION> [<COMMENT>]",
+					Action:    versionAnnotate,
+					Before: func(c *cli.Context) error {
+						if _, err := strconv.Atoi(c.Args().Get(1)); err != nil {
+							return cli.NewExitError("Please specify version.", -1)
+						}
+						return nil
+					},
+				},
+				cli.Command{
+					Name:      "lock",
+					Usage:     "Lock a specified VERSION, preventing further changes",
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>) <VERSION>",
+					Action:    versionLock,
+					Before: func(c *cli.Context) error {
+						if _, err := strconv.Atoi(c.Args().Get(1)); err != nil {
+							return cli.NewExitError("Please specify version to lock.", -1)
+						}
+						return nil
 					},
 				},
 			},
@@ -131,6 +715,162 @@ func main() {
 					Name:   "list",
 					Usage:  "List services associated with account",
 					Action: serviceList,
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "type",
+							Usage: "Only list services of `TYPE`: vcl or wasm.",
+						},
+					},
+					Before: func(c *cli.Context) error {
+						if c.IsSet("type") && c.String("type") != "vcl" && c.String("type") != "wasm" {
+							return cli.NewExitError("--type must be one of: vcl, wasm.", -1)
+						}
+						return checkOutputFormat(c)
+					},
+				},
+				cli.Command{
+					Name:      "search",
+					Usage:     "List services whose names contain QUERY, ignoring case",
+					ArgsUsage: "<QUERY>",
+					Action:    serviceSearch,
+					Flags: []cli.Flag{
+						cli.BoolFlag{
+							Name:  "regex",
+							Usage: "Interpret QUERY as a regular expression.",
+						},
+					},
+					Before: func(c *cli.Context) error {
+						if !c.Args().Present() {
+							return cli.NewExitError("Please specify search query.", -1)
+						}
+						return checkOutputFormat(c)
+					},
+				},
+				cli.Command{
+					Name:      "export",
+					Usage:     "Print the config of the active version of a service in config file format",
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>)",
+					Action:    serviceExport,
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "vcl-dir",
+							Usage: "Write VCLs to files in `DIR` and reference them from the config, rather than including their content.",
+						},
+					},
+					Before: func(c *cli.Context) error {
+						if !c.Args().Present() {
+							return cli.NewExitError("Please specify service.", -1)
+						}
+						return nil
+					},
+				},
+				cli.Command{
+					Name:      "create",
+					Usage:     "Create a new service with the given NAME",
+					ArgsUsage: "<SERVICE_NAME>",
+					Action:    serviceCreate,
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "comment",
+							Usage: "Optional comment to attach to the service.",
+						},
+					},
+					Before: func(c *cli.Context) error {
+						if !util.IsInteractive() && !c.GlobalBool("assume-yes") {
+							return cli.NewExitError(util.ErrNonInteractive.Error(), -1)
+						}
+						if !c.Args().Present() {
+							return cli.NewExitError("Please specify service name.", -1)
+						}
+						return nil
+					},
+				},
+				cli.Command{
+					Name:      "clone",
+					Usage:     "Create a new service with a copy of the active config of an existing service",
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>) <NEW_NAME>",
+					Action:    serviceClone,
+					Flags: []cli.Flag{
+						cli.StringSliceFlag{
+							Name:  "domain",
+							Usage: "Add `DOMAIN` to the new service. Domains are not copied from the source service, as a domain can only belong to one service. May be specified multiple times.",
+						},
+					},
+					Before: func(c *cli.Context) error {
+						if !util.IsInteractive() && !c.GlobalBool("assume-yes") {
+							return cli.NewExitError(util.ErrNonInteractive.Error(), -1)
+						}
+						if len(c.Args()) < 2 {
+							return cli.NewExitError("Please specify source service and new service name.", -1)
+						}
+						return nil
+					},
+				},
+				cli.Command{
+					Name:      "rename",
+					Usage:     "Rename a service",
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>) <NEW_NAME>",
+					Action:    serviceRename,
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "comment",
+							Usage: "Also replace the comment of the service.",
+						},
+					},
+					Before: func(c *cli.Context) error {
+						if !util.IsInteractive() && !c.GlobalBool("assume-yes") {
+							return cli.NewExitError(util.ErrNonInteractive.Error(), -1)
+						}
+						if len(c.Args()) < 2 {
+							return cli.NewExitError("Please specify service and new service name.", -1)
+						}
+						return nil
+					},
+				},
+				cli.Command{
+					Name:      "disable",
+					Usage:     "Stop a service from serving traffic by deactivating its active version, which is remembered by service enable",
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>)",
+					Action:    serviceDisable,
+					Before: func(c *cli.Context) error {
+						if !util.IsInteractive() && !c.GlobalBool("assume-yes") {
+							return cli.NewExitError(util.ErrNonInteractive.Error(), -1)
+						}
+						if !c.Args().Present() {
+							return cli.NewExitError("Please specify service.", -1)
+						}
+						return nil
+					},
+				},
+				cli.Command{
+					Name:      "enable",
+					Usage:     "Re-activate the version a service was serving before service disable",
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>)",
+					Action:    serviceEnable,
+					Before: func(c *cli.Context) error {
+						if !util.IsInteractive() && !c.GlobalBool("assume-yes") {
+							return cli.NewExitError(util.ErrNonInteractive.Error(), -1)
+						}
+						if !c.Args().Present() {
+							return cli.NewExitError("Please specify service.", -1)
+						}
+						return nil
+					},
+				},
+				cli.Command{
+					Name:      "delete",
+					Usage:     "Delete a service",
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>)",
+					Action:    serviceDelete,
+					Before: func(c *cli.Context) error {
+						if !util.IsInteractive() && !c.GlobalBool("assume-yes") {
+							return cli.NewExitError(util.ErrNonInteractive.Error(), -1)
+						}
+						if !c.Args().Present() {
+							return cli.NewExitError("Please specify service.", -1)
+						}
+						return nil
+					},
 				},
 			},
 		},
@@ -150,28 +890,152 @@ func main() {
 					Name:      "list",
 					Usage:     "List dictionaries associated with a given service",
 					Action:    dictionaryList,
-					ArgsUsage: "<SERVICE_NAME>",
+					Before:    checkOutputFormat,
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>)",
+				},
+				cli.Command{
+					Name:      "create",
+					Usage:     "Create a dictionary on a new draft version",
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>) <DICTIONARY_NAME>",
+					Action:    dictionaryCreate,
+					Flags: []cli.Flag{
+						cli.IntFlag{
+							Name:  "version",
+							Usage: "Create the dictionary on an existing draft `VERSION` rather than cloning the active version.",
+						},
+						cli.BoolFlag{
+							Name:  "write-only",
+							Usage: "Create a private dictionary, whose item values cannot be read back. This cannot be changed later.",
+						},
+					},
+					Before: func(c *cli.Context) error {
+						if c.NArg() < 2 {
+							return cli.NewExitError("Please specify dictionary name.", -1)
+						}
+						return nil
+					},
+				},
+				cli.Command{
+					Name:      "delete",
+					Usage:     "Delete a dictionary on a new draft version",
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>) <DICTIONARY_NAME>",
+					Action:    dictionaryDelete,
+					Flags: []cli.Flag{
+						cli.IntFlag{
+							Name:  "version",
+							Usage: "Delete the dictionary from an existing draft `VERSION` rather than cloning the active version.",
+						},
+					},
+					Before: func(c *cli.Context) error {
+						if !util.IsInteractive() && !c.GlobalBool("assume-yes") {
+							return cli.NewExitError(util.ErrNonInteractive.Error(), -1)
+						}
+						if c.NArg() < 2 {
+							return cli.NewExitError("Please specify dictionary name.", -1)
+						}
+						return nil
+					},
 				},
 				cli.Command{
 					Name:      "item-add",
 					Usage:     "Add an item to a dictionary",
 					Action:    dictionaryAddItem,
-					ArgsUsage: "<SERVICE_NAME> <DICTIONARY_NAME> <ITEM_KEY> <ITEM_VALUE>",
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>) <DICTIONARY_NAME> <ITEM_KEY> <ITEM_VALUE>",
+					Flags: []cli.Flag{
+						cli.BoolFlag{
+							Name:  "upsert",
+							Usage: "Update the item if it already exists.",
+						},
+					},
+				},
+				cli.Command{
+					Name:      "item-update",
+					Usage:     "Update the value of an existing item in a dictionary",
+					Action:    dictionaryUpdateItem,
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>) <DICTIONARY_NAME> <ITEM_KEY> <ITEM_VALUE>",
 				},
 				cli.Command{
 					Name:      "item-rm",
 					Usage:     "Remove an item from a dictionary",
 					Action:    dictionaryRemoveItem,
-					ArgsUsage: "<SERVICE_NAME> <DICTIONARY_NAME> <ITEM_KEY>",
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>) <DICTIONARY_NAME> <ITEM_KEY>",
 				},
 				cli.Command{
-					Name:      "item-ls",
-					Usage:     "List items in a dictionary",
-					Action:    dictionaryListItems,
-					ArgsUsage: "<SERVICE_NAME> <DICTIONARY_NAME>",
+					Name:      "item-bulk",
+					Usage:     "Add or update dictionary items from a FILE of key=value pairs, of key,value records if FILE ends in .csv, or of JSON if FILE ends in .json",
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>) <DICTIONARY_NAME> <FILE>",
+					Action:    dictionaryBulkItems,
+					Flags: append([]cli.Flag{
+						cli.BoolFlag{
+							Name:  "delete-missing",
+							Usage: "Remove items from the dictionary which are not present in FILE.",
+						},
+					}, dictionaryWriteFlags...),
+					Before: func(c *cli.Context) error {
+						if c.NArg() < 3 {
+							return cli.NewExitError("Please specify dictionary and file.", -1)
+						}
+						return nil
+					},
 				},
-			},
-		},
+				cli.Command{
+					Name:      "sync",
+					Usage:     "Make a dictionary match FILE exactly, creating, updating, and deleting items in batches. FILE is read as for item-bulk, or as JSON if it ends in .json",
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>) <DICTIONARY_NAME> <FILE>",
+					Action:    dictionarySync,
+					Flags:     dictionaryWriteFlags,
+					Before: func(c *cli.Context) error {
+						if c.NArg() < 3 {
+							return cli.NewExitError("Please specify dictionary and file.", -1)
+						}
+						return nil
+					},
+				},
+				cli.Command{
+					Name:      "copy",
+					Usage:     "Copy all items of a dictionary into another dictionary, which may be on another service",
+					ArgsUsage: "(<SRC_SERVICE_NAME> | <SRC_SERVICE_ID>) <SRC_DICTIONARY_NAME> (<DST_SERVICE_NAME> | <DST_SERVICE_ID>) <DST_DICTIONARY_NAME>",
+					Action:    dictionaryCopy,
+					Flags: append([]cli.Flag{
+						cli.BoolFlag{
+							Name:  "overwrite",
+							Usage: "Replace the values of items which already exist in the destination, rather than skipping them.",
+						},
+					}, dictionaryWriteFlags...),
+					Before: func(c *cli.Context) error {
+						if c.NArg() < 4 {
+							return cli.NewExitError("Please specify source dictionary, destination service, and destination dictionary.", -1)
+						}
+						return nil
+					},
+				},
+				cli.Command{
+					Name:      "item-export",
+					Usage:     "Export all items in a dictionary as CSV, sorted by key",
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>) <DICTIONARY_NAME>",
+					Action:    dictionaryExportItems,
+					Flags: []cli.Flag{
+						cli.BoolFlag{
+							Name:  "json",
+							Usage: "Export items as JSON rather than CSV.",
+						},
+					},
+				},
+				cli.Command{
+					Name:      "item-ls",
+					Usage:     "List items in a dictionary",
+					Action:    dictionaryListItems,
+					Before:    checkOutputFormat,
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>) <DICTIONARY_NAME>",
+					Flags: []cli.Flag{
+						cli.BoolFlag{
+							Name:  "count",
+							Usage: "Print only the number of items in the dictionary.",
+						},
+					},
+				},
+			},
+		},
 		cli.Command{
 			Name:  "acl",
 			Usage: "Manage Edge ACLs.",
@@ -188,33 +1052,1136 @@ func main() {
 					Name:      "list",
 					Usage:     "List acls associated with a given service",
 					Action:    aclList,
-					ArgsUsage: "<SERVICE_NAME>",
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>)",
 				},
 				cli.Command{
 					Name:      "entry-add",
+					Aliases:   []string{"item-add"},
 					Usage:     "Add an entry to a acl",
 					Action:    aclAddEntry,
-					ArgsUsage: "<SERVICE_NAME> <ACL_NAME> <IP>[/<MASK>]",
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>) <ACL_NAME> <IP>[/<MASK>]",
+					Flags: []cli.Flag{
+						cli.BoolFlag{
+							Name:  "negate",
+							Usage: "Negate the entry, excluding the address from the ACL.",
+						},
+						cli.StringFlag{
+							Name:  "comment",
+							Usage: "Optional comment to attach to the entry.",
+						},
+					},
 				},
 				cli.Command{
 					Name:      "entry-rm",
+					Aliases:   []string{"item-rm"},
 					Usage:     "Remove an entry from an acl",
 					Action:    aclRemoveEntry,
-					ArgsUsage: "<SERVICE_NAME> <ACL_NAME> <IP>[/<MASK>]",
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>) <ACL_NAME> <IP>[/<MASK>]",
 				},
 				cli.Command{
 					Name:      "entry-ls",
+					Aliases:   []string{"item-ls"},
 					Usage:     "List entries in an acl",
 					Action:    aclListEntries,
-					ArgsUsage: "<SERVICE_NAME> <ACL_NAME>",
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>) <ACL_NAME>",
+				},
+				cli.Command{
+					Name:      "entry-bulk",
+					Aliases:   []string{"item-bulk"},
+					Usage:     "Add or update acl entries from a FILE containing one [!]<IP>[/<MASK>] [<COMMENT>] per line",
+					Action:    aclBulkEntries,
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>) <ACL_NAME> <FILE>",
+					Flags: []cli.Flag{
+						cli.BoolFlag{
+							Name:  "delete-missing",
+							Usage: "Remove entries from the acl which are not present in FILE.",
+						},
+					},
+					Before: func(c *cli.Context) error {
+						if c.NArg() < 3 {
+							return cli.NewExitError("Please specify acl and file.", -1)
+						}
+						return nil
+					},
+				},
+			},
+		},
+		cli.Command{
+			Name:  "backend",
+			Usage: "Manage backends.",
+			Before: func(c *cli.Context) error {
+				// less than 2 here since the subcommand is the first Arg
+				if len(c.Args()) < 2 {
+					return cli.NewExitError("Please specify service.", -1)
+				}
+				return nil
+			},
+			Subcommands: cli.Commands{
+				cli.Command{
+					Name:      "list",
+					Usage:     "List backends on the active version of a given service",
+					Action:    backendList,
+					Before:    checkOutputFormat,
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>)",
+				},
+				cli.Command{
+					Name:      "add",
+					Usage:     "Add a backend on a new draft version",
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>) <BACKEND_NAME> <ADDRESS>",
+					Action:    backendAdd,
+					Flags:     append(backendFlags, draftFlags...),
+					Before: func(c *cli.Context) error {
+						if c.NArg() < 3 {
+							return cli.NewExitError("Please specify backend name and address.", -1)
+						}
+						return checkActivate(c)
+					},
+				},
+				cli.Command{
+					Name:      "update",
+					Usage:     "Update a backend on a new draft version",
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>) <BACKEND_NAME>",
+					Action:    backendUpdate,
+					Flags: append(append([]cli.Flag{
+						cli.StringFlag{
+							Name:  "address",
+							Usage: "Hostname or IP `ADDRESS` of the backend.",
+						},
+					}, backendFlags...), draftFlags...),
+					Before: func(c *cli.Context) error {
+						if c.NArg() < 2 {
+							return cli.NewExitError("Please specify backend name.", -1)
+						}
+						return checkActivate(c)
+					},
+				},
+				cli.Command{
+					Name:      "rm",
+					Usage:     "Remove a backend on a new draft version",
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>) <BACKEND_NAME>",
+					Action:    backendRemove,
+					Flags:     draftFlags,
+					Before: func(c *cli.Context) error {
+						if c.NArg() < 2 {
+							return cli.NewExitError("Please specify backend name.", -1)
+						}
+						return checkActivate(c)
+					},
+				},
+			},
+		},
+		cli.Command{
+			Name:  "director",
+			Usage: "Manage directors, which load balance across groups of backends.",
+			Before: func(c *cli.Context) error {
+				// less than 2 here since the subcommand is the first Arg
+				if len(c.Args()) < 2 {
+					return cli.NewExitError("Please specify service.", -1)
+				}
+				return nil
+			},
+			Subcommands: cli.Commands{
+				cli.Command{
+					Name:      "list",
+					Usage:     "List directors and their member backends on the active version of a given service",
+					Action:    directorList,
+					Before:    checkOutputFormat,
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>)",
+				},
+				cli.Command{
+					Name:      "add",
+					Usage:     "Add a director on a new draft version",
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>) <DIRECTOR_NAME>",
+					Action:    directorAdd,
+					Flags: append([]cli.Flag{
+						cli.StringFlag{
+							Name:  "type",
+							Value: "random",
+							Usage: "Balancing `TYPE`. One of: random, hash, client.",
+						},
+						cli.StringFlag{
+							Name:  "backends",
+							Usage: "Comma-separated list of existing `BACKENDS` to add to the director.",
+						},
+						cli.IntFlag{
+							Name:  "quorum",
+							Value: 75,
+							Usage: "`PERCENT` of backends which must be healthy for the director to be considered healthy.",
+						},
+						cli.IntFlag{
+							Name:  "retries",
+							Value: 5,
+							Usage: "Number of `TIMES` to try another backend if a request fails.",
+						},
+						cli.StringFlag{
+							Name:  "comment",
+							Usage: "`COMMENT` for the director.",
+						},
+					}, draftFlags...),
+					Before: checkDirectorAdd,
+				},
+				cli.Command{
+					Name:      "rm",
+					Usage:     "Remove a director on a new draft version. Member backends are left in place.",
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>) <DIRECTOR_NAME>",
+					Action:    directorRemove,
+					Flags:     draftFlags,
+					Before: func(c *cli.Context) error {
+						if c.NArg() < 2 {
+							return cli.NewExitError("Please specify director name.", -1)
+						}
+						return checkActivate(c)
+					},
+				},
+			},
+		},
+		cli.Command{
+			Name:  "healthcheck",
+			Usage: "Manage health checks.",
+			Before: func(c *cli.Context) error {
+				// less than 2 here since the subcommand is the first Arg
+				if len(c.Args()) < 2 {
+					return cli.NewExitError("Please specify service.", -1)
+				}
+				return nil
+			},
+			Subcommands: cli.Commands{
+				cli.Command{
+					Name:      "list",
+					Usage:     "List health checks on the active version of a given service",
+					Action:    healthCheckList,
+					Before:    checkOutputFormat,
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>)",
+				},
+				cli.Command{
+					Name:      "add",
+					Usage:     "Add a health check on a new draft version",
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>) <HEALTHCHECK_NAME>",
+					Action:    healthCheckAdd,
+					Flags:     append(healthCheckFlags, draftFlags...),
+					Before: func(c *cli.Context) error {
+						if c.NArg() < 2 {
+							return cli.NewExitError("Please specify health check name.", -1)
+						}
+						return checkActivate(c)
+					},
+				},
+				cli.Command{
+					Name:      "update",
+					Usage:     "Update a health check on a new draft version",
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>) <HEALTHCHECK_NAME>",
+					Action:    healthCheckUpdate,
+					Flags:     append(healthCheckFlags, draftFlags...),
+					Before: func(c *cli.Context) error {
+						if c.NArg() < 2 {
+							return cli.NewExitError("Please specify health check name.", -1)
+						}
+						return checkActivate(c)
+					},
+				},
+				cli.Command{
+					Name:      "rm",
+					Usage:     "Remove a health check on a new draft version",
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>) <HEALTHCHECK_NAME>",
+					Action:    healthCheckRemove,
+					Flags:     draftFlags,
+					Before: func(c *cli.Context) error {
+						if c.NArg() < 2 {
+							return cli.NewExitError("Please specify health check name.", -1)
+						}
+						return checkActivate(c)
+					},
+				},
+			},
+		},
+		cli.Command{
+			Name:  "request-settings",
+			Usage: "Manage request settings.",
+			Before: func(c *cli.Context) error {
+				// less than 2 here since the subcommand is the first Arg
+				if len(c.Args()) < 2 {
+					return cli.NewExitError("Please specify service.", -1)
+				}
+				return nil
+			},
+			Subcommands: cli.Commands{
+				cli.Command{
+					Name:      "list",
+					Usage:     "List request settings on the active version of a given service",
+					Action:    requestSettingList,
+					Before:    checkOutputFormat,
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>)",
+				},
+				cli.Command{
+					Name:      "add",
+					Usage:     "Add a request setting on a new draft version",
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>) <REQUEST_SETTING_NAME>",
+					Action:    requestSettingAdd,
+					Flags:     append(requestSettingFlags, draftFlags...),
+					Before:    checkRequestSetting,
+				},
+				cli.Command{
+					Name:      "update",
+					Usage:     "Update a request setting on a new draft version",
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>) <REQUEST_SETTING_NAME>",
+					Action:    requestSettingUpdate,
+					Flags:     append(requestSettingFlags, draftFlags...),
+					Before:    checkRequestSetting,
+				},
+				cli.Command{
+					Name:      "rm",
+					Usage:     "Remove a request setting on a new draft version",
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>) <REQUEST_SETTING_NAME>",
+					Action:    requestSettingRemove,
+					Flags:     draftFlags,
+					Before: func(c *cli.Context) error {
+						if c.NArg() < 2 {
+							return cli.NewExitError("Please specify request setting name.", -1)
+						}
+						return checkActivate(c)
+					},
+				},
+			},
+		},
+		cli.Command{
+			Name:  "response-object",
+			Usage: "Manage synthetic response objects.",
+			Before: func(c *cli.Context) error {
+				// less than 2 here since the subcommand is the first Arg
+				if len(c.Args()) < 2 {
+					return cli.NewExitError("Please specify service.", -1)
+				}
+				return nil
+			},
+			Subcommands: cli.Commands{
+				cli.Command{
+					Name:      "list",
+					Usage:     "List response objects on the active version of a given service",
+					Action:    responseObjectList,
+					Before:    checkOutputFormat,
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>)",
+				},
+				cli.Command{
+					Name:      "add",
+					Usage:     "Add a response object on a new draft version",
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>) <RESPONSE_OBJECT_NAME>",
+					Action:    responseObjectAdd,
+					Flags: append([]cli.Flag{
+						cli.IntFlag{
+							Name:  "status",
+							Value: 200,
+							Usage: "HTTP `STATUS` code of the response.",
+						},
+						cli.StringFlag{
+							Name:  "response",
+							Value: "OK",
+							Usage: "HTTP `REASON` phrase of the response.",
+						},
+						cli.StringFlag{
+							Name:  "content",
+							Usage: "Body of the response.",
+						},
+						cli.StringFlag{
+							Name:  "content-file",
+							Usage: "Read the body of the response from `FILE`.",
+						},
+						cli.StringFlag{
+							Name:  "content-type",
+							Usage: "`TYPE` to send in the Content-Type header, such as text/html.",
+						},
+						cli.StringFlag{
+							Name:  "request-condition",
+							Usage: "Serve the response to requests matching condition `NAME`.",
+						},
+						cli.StringFlag{
+							Name:  "cache-condition",
+							Usage: "Serve the response when cache condition `NAME` matches.",
+						},
+					}, draftFlags...),
+					Before: checkResponseObjectAdd,
+				},
+				cli.Command{
+					Name:      "rm",
+					Usage:     "Remove a response object on a new draft version",
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>) <RESPONSE_OBJECT_NAME>",
+					Action:    responseObjectRemove,
+					Flags:     draftFlags,
+					Before: func(c *cli.Context) error {
+						if c.NArg() < 2 {
+							return cli.NewExitError("Please specify response object name.", -1)
+						}
+						return checkActivate(c)
+					},
+				},
+			},
+		},
+		cli.Command{
+			Name:  "cache-settings",
+			Usage: "Manage cache settings.",
+			Before: func(c *cli.Context) error {
+				// less than 2 here since the subcommand is the first Arg
+				if len(c.Args()) < 2 {
+					return cli.NewExitError("Please specify service.", -1)
+				}
+				return nil
+			},
+			Subcommands: cli.Commands{
+				cli.Command{
+					Name:      "list",
+					Usage:     "List cache settings on the active version of a given service",
+					Action:    cacheSettingList,
+					Before:    checkOutputFormat,
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>)",
+				},
+				cli.Command{
+					Name:      "add",
+					Usage:     "Add a cache setting on a new draft version",
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>) <CACHE_SETTING_NAME>",
+					Action:    cacheSettingAdd,
+					Flags:     append(cacheSettingFlags, draftFlags...),
+					Before:    checkCacheSetting,
+				},
+				cli.Command{
+					Name:      "update",
+					Usage:     "Update a cache setting on a new draft version",
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>) <CACHE_SETTING_NAME>",
+					Action:    cacheSettingUpdate,
+					Flags:     append(cacheSettingFlags, draftFlags...),
+					Before:    checkCacheSetting,
+				},
+				cli.Command{
+					Name:      "rm",
+					Usage:     "Remove a cache setting on a new draft version",
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>) <CACHE_SETTING_NAME>",
+					Action:    cacheSettingRemove,
+					Flags:     draftFlags,
+					Before: func(c *cli.Context) error {
+						if c.NArg() < 2 {
+							return cli.NewExitError("Please specify cache setting name.", -1)
+						}
+						return checkActivate(c)
+					},
+				},
+			},
+		},
+		cli.Command{
+			Name:  "logging",
+			Usage: "Manage logging endpoints.",
+			Before: func(c *cli.Context) error {
+				// less than 2 here since the subcommand is the first Arg
+				if len(c.Args()) < 2 {
+					return cli.NewExitError("Please specify service.", -1)
+				}
+				return nil
+			},
+			Subcommands: cli.Commands{
+				cli.Command{
+					Name:      "list",
+					Usage:     "List logging endpoints of all types on the active version of a given service",
+					Action:    loggingList,
+					Before:    checkOutputFormat,
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>)",
+				},
+				cli.Command{
+					Name:  "add",
+					Usage: "Add a logging endpoint on a new draft version",
+					Subcommands: cli.Commands{
+						cli.Command{
+							Name:      "s3",
+							Usage:     "Add an S3 logging endpoint",
+							ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>) <NAME>",
+							Action:    loggingAddS3,
+							Flags: append([]cli.Flag{
+								cli.StringFlag{
+									Name:  "bucket",
+									Usage: "`BUCKET` to write logs to.",
+								},
+								cli.StringFlag{
+									Name:  "domain",
+									Usage: "S3 `DOMAIN` to use, if not the default.",
+								},
+								cli.StringFlag{
+									Name:  "access-key",
+									Usage: "Access `KEY` for the bucket.",
+								},
+								cli.StringFlag{
+									Name:  "secret-key",
+									Usage: "Secret `KEY` for the bucket.",
+								},
+								cli.StringFlag{
+									Name:  "timestamp-format",
+									Usage: "strftime `FORMAT` for the timestamp in log file names.",
+								},
+							}, fileLoggingFlags...),
+							Before: checkLoggingAdd("bucket"),
+						},
+						cli.Command{
+							Name:      "syslog",
+							Usage:     "Add a syslog logging endpoint",
+							ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>) <NAME>",
+							Action:    loggingAddSyslog,
+							Flags: append([]cli.Flag{
+								cli.StringFlag{
+									Name:  "address",
+									Usage: "Hostname or IP `ADDRESS` of the syslog server.",
+								},
+								cli.IntFlag{
+									Name:  "port",
+									Value: 514,
+									Usage: "`PORT` of the syslog server.",
+								},
+								cli.BoolFlag{
+									Name:  "use-tls",
+									Usage: "Connect to the syslog server using TLS.",
+								},
+								cli.StringFlag{
+									Name:  "tls-hostname",
+									Usage: "`HOSTNAME` to verify the server's certificate against.",
+								},
+								cli.StringFlag{
+									Name:  "tls-ca-cert",
+									Usage: "PEM encoded `CERT` of the CA which signed the server's certificate.",
+								},
+								cli.StringFlag{
+									Name:  "token",
+									Usage: "`TOKEN` to prefix each log line with.",
+								},
+								cli.StringFlag{
+									Name:  "format",
+									Usage: "Apache style log `FORMAT`.",
+								},
+								cli.StringFlag{
+									Name:  "response-condition",
+									Usage: "Only log responses matching the `CONDITION`.",
+								},
+							}, draftFlags...),
+							Before: checkLoggingAdd("address"),
+						},
+						cli.Command{
+							Name:      "gcs",
+							Usage:     "Add a Google Cloud Storage logging endpoint",
+							ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>) <NAME>",
+							Action:    loggingAddGCS,
+							Flags: append([]cli.Flag{
+								cli.StringFlag{
+									Name:  "bucket",
+									Usage: "`BUCKET` to write logs to.",
+								},
+								cli.StringFlag{
+									Name:  "user",
+									Usage: "Service account `EMAIL` to write logs as.",
+								},
+								cli.StringFlag{
+									Name:  "secret-key",
+									Usage: "Private `KEY` of the service account.",
+								},
+								cli.StringFlag{
+									Name:  "timestamp-format",
+									Usage: "strftime `FORMAT` for the timestamp in log file names.",
+								},
+							}, fileLoggingFlags...),
+							Before: checkLoggingAdd("bucket"),
+						},
+					},
+				},
+				cli.Command{
+					Name:      "rm",
+					Usage:     "Remove a logging endpoint on a new draft version",
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>) <TYPE> <NAME>",
+					Action:    loggingRemove,
+					Flags:     draftFlags,
+					Before: func(c *cli.Context) error {
+						if c.NArg() < 3 {
+							return cli.NewExitError("Please specify logging endpoint type and name.", -1)
+						}
+						if !util.StringInSlice(c.Args().Get(1), loggingTypes) {
+							return cli.NewExitError(fmt.Sprintf("Logging endpoint type must be one of: %s", strings.Join(loggingTypes, ", ")), -1)
+						}
+						return checkActivate(c)
+					},
+				},
+			},
+		},
+		cli.Command{
+			Name:  "condition",
+			Usage: "Manage conditions.",
+			Before: func(c *cli.Context) error {
+				// less than 2 here since the subcommand is the first Arg
+				if len(c.Args()) < 2 {
+					return cli.NewExitError("Please specify service.", -1)
+				}
+				return nil
+			},
+			Subcommands: cli.Commands{
+				cli.Command{
+					Name:      "list",
+					Usage:     "List conditions on the active version of a given service",
+					Action:    conditionList,
+					Before:    checkOutputFormat,
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>)",
+				},
+				cli.Command{
+					Name:      "add",
+					Usage:     "Add a condition on a new draft version, and validate the draft",
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>) <CONDITION_NAME>",
+					Action:    conditionAdd,
+					Flags: append([]cli.Flag{
+						cli.StringFlag{
+							Name:  "type",
+							Value: "request",
+							Usage: "`TYPE` of the condition. One of: request, response, cache.",
+						},
+						cli.StringFlag{
+							Name:  "statement",
+							Usage: "VCL `STATEMENT` the condition evaluates, such as req.url ~ \"^/api/\". Required.",
+						},
+						cli.IntFlag{
+							Name:  "priority",
+							Value: 10,
+							Usage: "`PRIORITY` of the condition. Lower priorities are evaluated first.",
+						},
+						cli.StringFlag{
+							Name:  "comment",
+							Usage: "Optional comment to attach to the condition.",
+						},
+					}, draftFlags...),
+					Before: checkConditionAdd,
+				},
+				cli.Command{
+					Name:      "rm",
+					Usage:     "Remove a condition on a new draft version",
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>) <CONDITION_NAME>",
+					Action:    conditionRemove,
+					Flags:     draftFlags,
+					Before: func(c *cli.Context) error {
+						if c.NArg() < 2 {
+							return cli.NewExitError("Please specify condition name.", -1)
+						}
+						return checkActivate(c)
+					},
+				},
+			},
+		},
+		cli.Command{
+			Name:  "gzip",
+			Usage: "Manage gzip rules.",
+			Before: func(c *cli.Context) error {
+				// less than 2 here since the subcommand is the first Arg
+				if len(c.Args()) < 2 {
+					return cli.NewExitError("Please specify service.", -1)
+				}
+				return nil
+			},
+			Subcommands: cli.Commands{
+				cli.Command{
+					Name:      "list",
+					Usage:     "List gzip rules on the active version of a given service",
+					Action:    gzipList,
+					Before:    checkOutputFormat,
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>)",
+				},
+				cli.Command{
+					Name:      "add",
+					Usage:     "Add a gzip rule on a new draft version",
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>) <GZIP_NAME>",
+					Action:    gzipAdd,
+					Flags: append([]cli.Flag{
+						cli.StringFlag{
+							Name:  "content-types",
+							Usage: "Comma-separated list of content `TYPES` to compress, such as text/html,application/json.",
+						},
+						cli.StringFlag{
+							Name:  "extensions",
+							Usage: "Comma-separated list of file `EXTENSIONS` to compress, such as css,js.",
+						},
+						cli.StringFlag{
+							Name:  "cache-condition",
+							Usage: "Only compress responses matching the cache condition `NAME`.",
+						},
+					}, draftFlags...),
+					Before: func(c *cli.Context) error {
+						if c.NArg() < 2 {
+							return cli.NewExitError("Please specify gzip rule name.", -1)
+						}
+						if c.String("content-types") == "" && c.String("extensions") == "" {
+							return cli.NewExitError("Please specify --content-types or --extensions.", -1)
+						}
+						return checkActivate(c)
+					},
+				},
+				cli.Command{
+					Name:      "rm",
+					Usage:     "Remove a gzip rule on a new draft version",
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>) <GZIP_NAME>",
+					Action:    gzipRemove,
+					Flags:     draftFlags,
+					Before: func(c *cli.Context) error {
+						if c.NArg() < 2 {
+							return cli.NewExitError("Please specify gzip rule name.", -1)
+						}
+						return checkActivate(c)
+					},
+				},
+			},
+		},
+		cli.Command{
+			Name:  "header",
+			Usage: "Manage header rules.",
+			Before: func(c *cli.Context) error {
+				// less than 2 here since the subcommand is the first Arg
+				if len(c.Args()) < 2 {
+					return cli.NewExitError("Please specify service.", -1)
+				}
+				return nil
+			},
+			Subcommands: cli.Commands{
+				cli.Command{
+					Name:      "list",
+					Usage:     "List header rules on the active version of a given service",
+					Action:    headerList,
+					Before:    checkOutputFormat,
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>)",
+				},
+				cli.Command{
+					Name:      "add",
+					Usage:     "Add a header rule on a new draft version",
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>) <HEADER_NAME>",
+					Action:    headerAdd,
+					Flags: append([]cli.Flag{
+						cli.StringFlag{
+							Name:  "action",
+							Value: "set",
+							Usage: "`ACTION` to take on the header. One of: set, append, delete, regex, regex_repeat.",
+						},
+						cli.StringFlag{
+							Name:  "type",
+							Value: "request",
+							Usage: "`TYPE` of object the header is applied to. One of: request, fetch, cache, response.",
+						},
+						cli.StringFlag{
+							Name:  "dst",
+							Usage: "`DESTINATION` header, such as http.X-Example. Required.",
+						},
+						cli.StringFlag{
+							Name:  "src",
+							Usage: "VCL expression to use as the `SOURCE` of the header value, such as \"example\" or client.ip.",
+						},
+						cli.StringFlag{
+							Name:  "regex",
+							Usage: "Regular expression matched against the source, for the regex actions.",
+						},
+						cli.StringFlag{
+							Name:  "substitution",
+							Usage: "Replacement for matches of --regex, for the regex actions.",
+						},
+						cli.IntFlag{
+							Name:  "priority",
+							Value: 100,
+							Usage: "`PRIORITY` of the header rule. Lower priorities are applied first.",
+						},
+						cli.BoolFlag{
+							Name:  "ignore-if-set",
+							Usage: "Do not change the header if it is already set.",
+						},
+						cli.StringFlag{
+							Name:  "request-condition",
+							Usage: "Only apply the rule to requests matching condition `NAME`.",
+						},
+						cli.StringFlag{
+							Name:  "response-condition",
+							Usage: "Only apply the rule to responses matching condition `NAME`.",
+						},
+						cli.StringFlag{
+							Name:  "cache-condition",
+							Usage: "Only apply the rule when cache condition `NAME` matches.",
+						},
+					}, draftFlags...),
+					Before: checkHeaderAdd,
+				},
+				cli.Command{
+					Name:      "rm",
+					Usage:     "Remove a header rule on a new draft version",
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>) <HEADER_NAME>",
+					Action:    headerRemove,
+					Flags:     draftFlags,
+					Before: func(c *cli.Context) error {
+						if c.NArg() < 2 {
+							return cli.NewExitError("Please specify header name.", -1)
+						}
+						return checkActivate(c)
+					},
+				},
+			},
+		},
+		cli.Command{
+			Name:  "domain",
+			Usage: "Manage domains.",
+			Before: func(c *cli.Context) error {
+				// less than 2 here since the subcommand is the first Arg
+				if len(c.Args()) < 2 {
+					return cli.NewExitError("Please specify service.", -1)
+				}
+				return nil
+			},
+			Subcommands: cli.Commands{
+				cli.Command{
+					Name:      "list",
+					Usage:     "List domains on the active version of a given service",
+					Action:    domainList,
+					Before:    checkOutputFormat,
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>)",
+				},
+				cli.Command{
+					Name:      "add",
+					Usage:     "Add a domain on a new draft version",
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>) <FQDN>",
+					Action:    domainAdd,
+					Flags: append([]cli.Flag{
+						cli.StringFlag{
+							Name:  "comment",
+							Usage: "Optional comment to attach to the domain.",
+						},
+					}, draftFlags...),
+					Before: func(c *cli.Context) error {
+						if c.NArg() < 2 {
+							return cli.NewExitError("Please specify domain.", -1)
+						}
+						return checkActivate(c)
+					},
+				},
+				cli.Command{
+					Name:      "check",
+					Aliases:   []string{"check-all"},
+					Usage:     "Check whether the DNS of each domain on the active version is pointed at Fastly. Exits non-zero if any are not.",
+					Action:    domainCheck,
+					Before:    checkOutputFormat,
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>)",
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "cname",
+							Value: "global.prod.fastly.net",
+							Usage: "The Fastly `HOSTNAME` domains are expected to be CNAME'd to, shown for reference.",
+						},
+					},
+				},
+				cli.Command{
+					Name:      "rm",
+					Usage:     "Remove a domain on a new draft version",
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>) <FQDN>",
+					Action:    domainRemove,
+					Flags:     draftFlags,
+					Before: func(c *cli.Context) error {
+						if c.NArg() < 2 {
+							return cli.NewExitError("Please specify domain.", -1)
+						}
+						return checkActivate(c)
+					},
+				},
+			},
+		},
+		cli.Command{
+			Name:  "vcl",
+			Usage: "Manage custom VCL.",
+			Before: func(c *cli.Context) error {
+				// less than 2 here since the subcommand is the first Arg
+				if len(c.Args()) < 2 {
+					return cli.NewExitError("Please specify service.", -1)
+				}
+				return nil
+			},
+			Subcommands: cli.Commands{
+				cli.Command{
+					Name:      "list",
+					Usage:     "List custom VCLs on the active version of a given service",
+					Action:    vclList,
+					Before:    checkOutputFormat,
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>)",
+				},
+				cli.Command{
+					Name:      "get",
+					Usage:     "Print the content of a custom VCL on the active version",
+					Action:    vclGet,
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>) <VCL_NAME>",
+					Before: func(c *cli.Context) error {
+						if c.NArg() < 2 {
+							return cli.NewExitError("Please specify VCL name.", -1)
+						}
+						return nil
+					},
+				},
+				cli.Command{
+					Name:      "generated",
+					Usage:     "Print the VCL generated by Fastly for a VERSION. VERSION defaults to the active version",
+					Action:    vclGenerated,
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>) [<VERSION>]",
+					Flags: []cli.Flag{
+						cli.IntFlag{
+							Name:  "diff",
+							Usage: "Show the diff of generated VCL from `OTHER_VERSION` to VERSION.",
+						},
+						cli.BoolFlag{
+							Name:  "no-pager",
+							Usage: "Print directly to stdout rather than through a pager.",
+						},
+					},
+				},
+				cli.Command{
+					Name:      "upload",
+					Usage:     "Upload a custom VCL from FILE on a new draft version",
+					Action:    vclUpload,
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>) <VCL_NAME> <FILE>",
+					Flags: append([]cli.Flag{
+						cli.BoolFlag{
+							Name:  "main",
+							Usage: "Mark the VCL as the main VCL for the service.",
+						},
+					}, draftFlags...),
+					Before: func(c *cli.Context) error {
+						if c.NArg() < 3 {
+							return cli.NewExitError("Please specify VCL name and file.", -1)
+						}
+						return checkActivate(c)
+					},
+				},
+			},
+		},
+		cli.Command{
+			Name:  "snippet",
+			Usage: "Manage VCL snippets.",
+			Before: func(c *cli.Context) error {
+				// less than 2 here since the subcommand is the first Arg
+				if len(c.Args()) < 2 {
+					return cli.NewExitError("Please specify service.", -1)
+				}
+				return nil
+			},
+			Subcommands: cli.Commands{
+				cli.Command{
+					Name:      "list",
+					Usage:     "List VCL snippets on the active version of a given service",
+					Action:    snippetList,
+					Before:    checkOutputFormat,
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>)",
+				},
+				cli.Command{
+					Name:      "get",
+					Usage:     "Print the content of a VCL snippet on the active version",
+					Action:    snippetGet,
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>) <SNIPPET_NAME>",
+					Before: func(c *cli.Context) error {
+						if c.NArg() < 2 {
+							return cli.NewExitError("Please specify snippet name.", -1)
+						}
+						return nil
+					},
+				},
+				cli.Command{
+					Name:      "upload",
+					Usage:     "Upload a VCL snippet from FILE. Existing dynamic snippets are updated live, otherwise changes are made on a new draft version",
+					Action:    snippetUpload,
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>) <SNIPPET_NAME> <FILE>",
+					Flags: append([]cli.Flag{
+						cli.StringFlag{
+							Name:  "type",
+							Usage: "Subroutine `TYPE` the snippet is inserted into, such as init, recv, fetch, deliver, or none. Required for new snippets.",
+						},
+						cli.IntFlag{
+							Name:  "priority",
+							Usage: "`PRIORITY` of the snippet. Lower priorities are inserted first. Defaults to 100 for new snippets.",
+						},
+						cli.BoolFlag{
+							Name:  "dynamic",
+							Usage: "Create the snippet as a dynamic snippet, whose content can be updated without a new version.",
+						},
+					}, draftFlags...),
+					Before: func(c *cli.Context) error {
+						if c.NArg() < 3 {
+							return cli.NewExitError("Please specify snippet name and file.", -1)
+						}
+						if err := checkSnippetType(c); err != nil {
+							return err
+						}
+						return checkActivate(c)
+					},
+				},
+			},
+		},
+		cli.Command{
+			Name:   "doctor",
+			Usage:  "Check that the config parses, an API key is set and accepted by the API, and a pager is available",
+			Action: doctor,
+		},
+		cli.Command{
+			Name:  "waf",
+			Usage: "Inspect the web application firewalls of a service.",
+			Before: func(c *cli.Context) error {
+				// less than 2 here since the subcommand is the first Arg
+				if len(c.Args()) < 2 {
+					return cli.NewExitError("Please specify service.", -1)
+				}
+				return nil
+			},
+			Subcommands: cli.Commands{
+				cli.Command{
+					Name:      "list",
+					Usage:     "List the WAFs on the active version of a given service, with the number of rules logging and blocking",
+					Action:    wafList,
+					Before:    checkOutputFormat,
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>)",
+				},
+				cli.Command{
+					Name:      "rules",
+					Usage:     "List the rule IDs enabled on a WAF and whether each logs or blocks",
+					Action:    wafRules,
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>) <WAF_ID>",
+					Before: func(c *cli.Context) error {
+						if c.NArg() < 2 {
+							return cli.NewExitError("Please specify WAF ID.", -1)
+						}
+						return checkOutputFormat(c)
+					},
+					Flags: []cli.Flag{
+						cli.BoolFlag{
+							Name:  "all",
+							Usage: "Also list disabled rules.",
+						},
+					},
+				},
+			},
+		},
+		cli.Command{
+			Name:  "tls",
+			Usage: "Inspect the TLS certificates of the account.",
+			Subcommands: cli.Commands{
+				cli.Command{
+					Name:   "list",
+					Usage:  "List Fastly managed and uploaded TLS certificates with their state and expiry",
+					Action: tlsList,
+					Before: func(c *cli.Context) error {
+						if c.IsSet("expiring") && c.Int("expiring") < 0 {
+							return cli.NewExitError("--expiring must not be negative.", -1)
+						}
+						return checkOutputFormat(c)
+					},
+					Flags: []cli.Flag{
+						cli.IntFlag{
+							Name:  "expiring",
+							Usage: "Only list certificates which expire within `DAYS`, or whose expiry is unknown, exiting non-zero if there are any.",
+						},
+					},
+				},
+			},
+		},
+		cli.Command{
+			Name:      "stats",
+			Usage:     "Print aggregated analytics for a service",
+			ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>)",
+			Action:    stats,
+			Before:    checkStats,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "from",
+					Usage: "Start of the window, as a unix timestamp or a relative time such as \"2 hours ago\". Defaults to one day ago.",
+				},
+				cli.StringFlag{
+					Name:  "to",
+					Usage: "End of the window, in the same formats as --from. Defaults to now.",
+				},
+				cli.StringFlag{
+					Name:  "by",
+					Usage: "Size of the periods to fetch: minute, hour or day.",
+				},
+				cli.BoolFlag{
+					Name:  "realtime",
+					Usage: "Fetch stats for the last 120 seconds from the real-time API.",
+				},
+				cli.StringSliceFlag{
+					Name:  "field",
+					Usage: "Only print metric `FIELD`. May be specified multiple times. One of: " + strings.Join(statsMetricNames(), ", "),
+				},
+			},
+		},
+		cli.Command{
+			Name:  "purge",
+			Usage: "Purge cached content.",
+			Subcommands: cli.Commands{
+				cli.Command{
+					Name:      "url",
+					Usage:     "Purge a single URL",
+					ArgsUsage: "<URL>",
+					Action:    purgeURL,
+					Flags: []cli.Flag{
+						cli.BoolFlag{
+							Name:  "soft",
+							Usage: "Mark content as stale rather than removing it.",
+						},
+					},
+					Before: func(c *cli.Context) error {
+						if !c.Args().Present() {
+							return cli.NewExitError("Please specify URL to purge.", -1)
+						}
+						return nil
+					},
+				},
+				cli.Command{
+					Name:      "key",
+					Usage:     "Purge all content tagged with a surrogate key",
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>) <SURROGATE_KEY>",
+					Action:    purgeKey,
+					Flags: []cli.Flag{
+						cli.BoolFlag{
+							Name:  "soft",
+							Usage: "Mark content as stale rather than removing it.",
+						},
+					},
+					Before: func(c *cli.Context) error {
+						if c.NArg() < 2 {
+							return cli.NewExitError("Please specify service and surrogate key.", -1)
+						}
+						return nil
+					},
+				},
+				cli.Command{
+					Name:      "keys",
+					Usage:     "Purge all content tagged with any of the surrogate keys in FILE, one per line",
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>) <FILE>",
+					Action:    purgeKeys,
+					Flags: []cli.Flag{
+						cli.BoolFlag{
+							Name:  "soft",
+							Usage: "Mark content as stale rather than removing it.",
+						},
+					},
+					Before: func(c *cli.Context) error {
+						if c.NArg() < 2 {
+							return cli.NewExitError("Please specify service and file.", -1)
+						}
+						return nil
+					},
+				},
+				cli.Command{
+					Name:      "all",
+					Usage:     "Purge all cached content for a service",
+					ArgsUsage: "(<SERVICE_NAME> | <SERVICE_ID>)",
+					Action:    purgeAll,
+					Before: func(c *cli.Context) error {
+						if !util.IsInteractive() && !c.GlobalBool("assume-yes") {
+							return cli.NewExitError(util.ErrNonInteractive.Error(), -1)
+						}
+						if !c.Args().Present() {
+							return cli.NewExitError("Please specify service.", -1)
+						}
+						return nil
+					},
 				},
 			},
 		},
 	}
+	describeTemplateFields(app.Commands, "")
 
 	err := app.Run(os.Args)
 	if err != nil {
-		fmt.Printf("Error starting app: %s", err)
+		fmt.Fprintf(os.Stderr, "Error starting app: %s", err)
 	}
 
 }
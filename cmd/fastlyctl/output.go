@@ -0,0 +1,374 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/alienth/fastlyctl/fastlyapi"
+	"github.com/alienth/fastlyctl/format"
+	"github.com/alienth/fastlyctl/util"
+	"github.com/alienth/go-fastly"
+	"github.com/urfave/cli"
+)
+
+// The following types define the shape of structured --output formats. They are kept
+// separate from the go-fastly types so that the output remains stable across
+// library upgrades.
+
+type serviceOutput struct {
+	ID            string `json:"id"`
+	Name          string `json:"name"`
+	Type          string `json:"type"`
+	Version       uint   `json:"version"`
+	ActiveVersion uint   `json:"active_version"`
+	Comment       string `json:"comment"`
+}
+
+type pushOutput struct {
+	Service    string `json:"service"`
+	OldVersion uint   `json:"old_version"`
+	NewVersion uint   `json:"new_version"`
+	Activated  bool   `json:"activated"`
+}
+
+type versionOutput struct {
+	Number  uint   `json:"number"`
+	Active  bool   `json:"active"`
+	Locked  bool   `json:"locked"`
+	Staging bool   `json:"staging"`
+	Comment string `json:"comment"`
+	Created string `json:"created_at"`
+	Updated string `json:"updated_at"`
+}
+
+type dictionaryOutput struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	WriteOnly bool   `json:"write_only"`
+}
+
+type dictionaryItemOutput struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type backendOutput struct {
+	Name    string `json:"name"`
+	Address string `json:"address"`
+	Port    uint   `json:"port"`
+	UseSSL  bool   `json:"use_ssl"`
+	Shield  string `json:"shield"`
+}
+
+type directorOutput struct {
+	Name     string   `json:"name"`
+	Type     string   `json:"type"`
+	Quorum   uint     `json:"quorum"`
+	Retries  uint     `json:"retries"`
+	Backends []string `json:"backends"`
+}
+
+type healthCheckOutput struct {
+	Name             string `json:"name"`
+	Method           string `json:"method"`
+	Host             string `json:"host"`
+	Path             string `json:"path"`
+	ExpectedResponse uint   `json:"expected_response"`
+	CheckInterval    uint   `json:"check_interval"`
+	Timeout          uint   `json:"timeout"`
+	Window           uint   `json:"window"`
+	Threshold        uint   `json:"threshold"`
+	Initial          uint   `json:"initial"`
+}
+
+type requestSettingOutput struct {
+	Name             string `json:"name"`
+	Action           string `json:"action"`
+	XFF              string `json:"xff"`
+	ForceMiss        bool   `json:"force_miss"`
+	ForceSSL         bool   `json:"force_ssl"`
+	TimerSupport     bool   `json:"timer_support"`
+	BypassBusyWait   bool   `json:"bypass_busy_wait"`
+	MaxStaleAge      int    `json:"max_stale_age"`
+	RequestCondition string `json:"request_condition"`
+}
+
+type responseObjectOutput struct {
+	Name             string `json:"name"`
+	Status           string `json:"status"`
+	Response         string `json:"response"`
+	ContentType      string `json:"content_type"`
+	Content          string `json:"content"`
+	RequestCondition string `json:"request_condition"`
+	CacheCondition   string `json:"cache_condition"`
+}
+
+type cacheSettingOutput struct {
+	Name           string `json:"name"`
+	Action         string `json:"action"`
+	TTL            uint   `json:"ttl"`
+	StaleTTL       uint   `json:"stale_ttl"`
+	CacheCondition string `json:"cache_condition"`
+}
+
+type loggingOutput struct {
+	Type        string `json:"type"`
+	Name        string `json:"name"`
+	Destination string `json:"destination"`
+}
+
+type gzipOutput struct {
+	Name           string `json:"name"`
+	ContentTypes   string `json:"content_types"`
+	Extensions     string `json:"extensions"`
+	CacheCondition string `json:"cache_condition"`
+}
+
+type conditionOutput struct {
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	Statement string `json:"statement"`
+	Priority  uint   `json:"priority"`
+}
+
+type headerOutput struct {
+	Name        string `json:"name"`
+	Action      string `json:"action"`
+	Type        string `json:"type"`
+	Destination string `json:"dst"`
+	Source      string `json:"src"`
+	Priority    uint   `json:"priority"`
+}
+
+type snippetOutput struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Priority uint   `json:"priority"`
+	Dynamic  bool   `json:"dynamic"`
+}
+
+type statOutput struct {
+	Metric string `json:"metric"`
+	Value  uint64 `json:"value"`
+}
+
+type domainOutput struct {
+	Name    string `json:"name"`
+	Comment string `json:"comment"`
+}
+
+type domainCheckOutput struct {
+	Name     string `json:"name"`
+	Expected string `json:"expected_cname"`
+	Actual   string `json:"actual_cname"`
+	Ready    bool   `json:"ready"`
+}
+
+type tlsOutput struct {
+	Domains []string `json:"domains"`
+	State   string   `json:"state"`
+	Issuer  string   `json:"issuer"`
+	Expires string   `json:"expires_at"`
+	Managed bool     `json:"managed"`
+}
+
+type wafOutput struct {
+	ID       string `json:"id"`
+	Disabled bool   `json:"disabled"`
+	Logging  uint   `json:"rules_logging"`
+	Blocking uint   `json:"rules_blocking"`
+	LastPush string `json:"last_push"`
+}
+
+type wafRuleOutput struct {
+	RuleID uint   `json:"rule_id"`
+	Status string `json:"status"`
+}
+
+type vclOutput struct {
+	Name string `json:"name"`
+	Main bool   `json:"main"`
+}
+
+func newServiceOutput(s *fastlyapi.Service) serviceOutput {
+	// The active version is left as 0 when no version is active.
+	activeVersion, _ := util.GetActiveVersion(&s.Service)
+	return serviceOutput{ID: s.ID, Name: s.Name, Type: serviceType(s), Version: s.Version, ActiveVersion: activeVersion, Comment: s.Comment}
+}
+
+func newVersionOutput(v *fastly.Version) versionOutput {
+	return versionOutput{Number: v.Number, Active: v.Active, Locked: v.Locked, Staging: v.Staging, Comment: v.Comment, Created: v.Created, Updated: v.Updated}
+}
+
+func newDictionaryOutput(d *fastlyapi.Dictionary) dictionaryOutput {
+	return dictionaryOutput{ID: d.ID, Name: d.Name, WriteOnly: d.WriteOnly}
+}
+
+func newDictionaryItemOutput(i *fastly.DictionaryItem) dictionaryItemOutput {
+	return dictionaryItemOutput{Key: i.Key, Value: i.Value}
+}
+
+func newBackendOutput(b *fastly.Backend) backendOutput {
+	return backendOutput{Name: b.Name, Address: b.Address, Port: b.Port, UseSSL: b.UseSSL, Shield: b.Shield}
+}
+
+func newDirectorOutput(d *fastlyapi.Director) directorOutput {
+	return directorOutput{Name: d.Name, Type: d.Type.String(), Quorum: d.Quorum, Retries: d.Retries, Backends: d.Backends}
+}
+
+func newHealthCheckOutput(h *fastly.HealthCheck) healthCheckOutput {
+	return healthCheckOutput{Name: h.Name, Method: h.Method, Host: h.Host, Path: h.Path, ExpectedResponse: h.ExpectedResponse, CheckInterval: h.CheckInterval, Timeout: h.Timeout, Window: h.Window, Threshold: h.Threshold, Initial: h.Initial}
+}
+
+func newGzipOutput(g *fastly.Gzip) gzipOutput {
+	return gzipOutput{Name: g.Name, ContentTypes: g.ContentTypes, Extensions: g.Extensions, CacheCondition: g.CacheCondition}
+}
+
+func newConditionOutput(c *fastly.Condition) conditionOutput {
+	conditionType, _ := c.Type.MarshalText()
+	return conditionOutput{Name: c.Name, Type: string(conditionType), Statement: c.Statement, Priority: c.Priority}
+}
+
+func newHeaderOutput(h *fastly.Header) headerOutput {
+	action, _ := h.Action.MarshalText()
+	headerType, _ := h.Type.MarshalText()
+	return headerOutput{Name: h.Name, Action: string(action), Type: string(headerType), Destination: h.Destination, Source: h.Source, Priority: h.Priority}
+}
+
+func newSnippetOutput(s *fastlyapi.Snippet) snippetOutput {
+	return snippetOutput{Name: s.Name, Type: string(s.Type), Priority: s.Priority, Dynamic: s.Dynamic == 1}
+}
+
+func newRequestSettingOutput(r *fastly.RequestSetting) requestSettingOutput {
+	return requestSettingOutput{Name: r.Name, Action: r.Action, XFF: r.XFF, ForceMiss: bool(r.ForceMiss), ForceSSL: bool(r.ForceSSL), TimerSupport: bool(r.TimerSupport), BypassBusyWait: bool(r.BypassBusyWait), MaxStaleAge: r.MaxStaleAge, RequestCondition: r.RequestCondition}
+}
+
+func newResponseObjectOutput(r *fastly.ResponseObject) responseObjectOutput {
+	return responseObjectOutput{Name: r.Name, Status: r.Status, Response: r.Response, ContentType: r.ContentType, Content: r.Content, RequestCondition: r.RequestCondition, CacheCondition: r.CacheCondition}
+}
+
+func newCacheSettingOutput(s *fastly.CacheSetting) cacheSettingOutput {
+	action, _ := s.Action.MarshalText()
+	return cacheSettingOutput{Name: s.Name, Action: string(action), TTL: s.TTL, StaleTTL: s.StaleTTL, CacheCondition: s.CacheCondition}
+}
+
+func newDomainOutput(d *fastly.Domain) domainOutput {
+	return domainOutput{Name: d.Name, Comment: d.Comment}
+}
+
+func newDomainCheckOutput(d *fastlyapi.DomainCheck, expected string) domainCheckOutput {
+	return domainCheckOutput{Name: d.Domain.Name, Expected: expected, Actual: d.CNAME, Ready: d.Success}
+}
+
+func newWAFOutput(w *fastlyapi.WAF) wafOutput {
+	return wafOutput{ID: w.ID, Disabled: w.Disabled, Logging: w.LoggingCount, Blocking: w.BlockingCount, LastPush: w.LastPush}
+}
+
+func newWAFRuleOutput(r *fastlyapi.WAFRuleStatus) wafRuleOutput {
+	return wafRuleOutput{RuleID: r.ModSecRuleID, Status: r.Status}
+}
+
+func newVCLOutput(v *fastly.VCL) vclOutput {
+	return vclOutput{Name: v.Name, Main: v.Main}
+}
+
+// templateFields maps each command which respects --output to the type of the
+// items it outputs, so that the fields available to templates can be listed in
+// the command's help.
+var templateFields = map[string]interface{}{
+	"push":                  pushOutput{},
+	"version list":          versionOutput{},
+	"service list":          serviceOutput{},
+	"service search":        serviceOutput{},
+	"dictionary list":       dictionaryOutput{},
+	"dictionary item-ls":    dictionaryItemOutput{},
+	"backend list":          backendOutput{},
+	"director list":         directorOutput{},
+	"healthcheck list":      healthCheckOutput{},
+	"request-settings list": requestSettingOutput{},
+	"response-object list":  responseObjectOutput{},
+	"cache-settings list":   cacheSettingOutput{},
+	"logging list":          loggingOutput{},
+	"condition list":        conditionOutput{},
+	"gzip list":             gzipOutput{},
+	"header list":           headerOutput{},
+	"domain list":           domainOutput{},
+	"domain check":          domainCheckOutput{},
+	"vcl list":              vclOutput{},
+	"snippet list":          snippetOutput{},
+	"waf list":              wafOutput{},
+	"waf rules":             wafRuleOutput{},
+	"tls list":              tlsOutput{},
+	"stats":                 statOutput{},
+}
+
+// describeTemplateFields adds the fields available to --output-template to
+// the description of each command in templateFields.
+func describeTemplateFields(commands []cli.Command, parent string) {
+	for i := range commands {
+		name := strings.TrimSpace(parent + " " + commands[i].Name)
+		if v, ok := templateFields[name]; ok {
+			fields := fmt.Sprintf("Fields available to --output-template: %s", strings.Join(format.Fields(v), " "))
+			if commands[i].Description != "" {
+				fields = commands[i].Description + "\n\n   " + fields
+			}
+			commands[i].Description = fields
+		}
+		describeTemplateFields(commands[i].Subcommands, name)
+	}
+}
+
+// outputFormat returns the output format selected with --output. Giving
+// --output-template, or --output go-template=TEMPLATE, selects templates.
+func outputFormat(c *cli.Context) string {
+	if c.GlobalIsSet("output-template") || strings.HasPrefix(c.GlobalString("output"), format.Template+"=") {
+		return format.Template
+	}
+	return c.GlobalString("output")
+}
+
+// outputTemplate returns the template text given with --output-template or
+// --output go-template=TEMPLATE.
+func outputTemplate(c *cli.Context) string {
+	if c.GlobalIsSet("output-template") {
+		return c.GlobalString("output-template")
+	}
+	return strings.TrimPrefix(c.GlobalString("output"), format.Template+"=")
+}
+
+// stdout is where printOutput writes. Commands which print messages as they
+// work point os.Stdout at stderr while producing structured output, so that
+// the output can be parsed.
+var stdout = os.Stdout
+
+// printOutput writes v to stdout in the selected output format.
+func printOutput(c *cli.Context, v interface{}) error {
+	var err error
+	if outputFormat(c) == format.Template {
+		err = format.WriteTemplate(stdout, outputTemplate(c), v)
+	} else {
+		err = format.Write(stdout, outputFormat(c), v)
+	}
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	return nil
+}
+
+// checkOutputFormat is used as the Before hook for commands which respect
+// --output.
+func checkOutputFormat(c *cli.Context) error {
+	if err := format.Validate(outputFormat(c)); err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	if outputFormat(c) == format.Template {
+		if outputTemplate(c) == "" {
+			return cli.NewExitError("Please specify a template with --output-template or --output go-template=TEMPLATE.", -1)
+		}
+		if _, err := format.ParseTemplate(outputTemplate(c)); err != nil {
+			return cli.NewExitError(err.Error(), -1)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/alienth/fastlyctl/fastlyapi"
+	"github.com/alienth/fastlyctl/format"
+	"gopkg.in/yaml.v2"
+)
+
+// roundTripYAML writes v as YAML and decodes it back into out, through JSON
+// so that the json tags of out's fields are used.
+func roundTripYAML(t *testing.T, v, out interface{}) {
+	var buf bytes.Buffer
+	if err := format.Write(&buf, format.YAML, v); err != nil {
+		t.Fatal(err)
+	}
+	var raw interface{}
+	if err := yaml.Unmarshal(buf.Bytes(), &raw); err != nil {
+		t.Fatalf("invalid YAML %q: %s", buf.String(), err)
+	}
+	converted, err := yamlToJSON(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := json.Marshal(converted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDirectorOutputYAML(t *testing.T) {
+	director := &fastlyapi.Director{Name: "d", Type: fastlyapi.DirectorTypeRandom, Quorum: 75, Retries: 5, Backends: []string{"b1", "b2"}}
+	want := []directorOutput{newDirectorOutput(director)}
+	var got []directorOutput
+	roundTripYAML(t, want, &got)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestTLSOutputYAML(t *testing.T) {
+	want := []tlsOutput{
+		{Domains: []string{"example.com", "www.example.com"}, State: "issued", Issuer: "Let's Encrypt", Expires: "2026-01-02T03:04:05Z", Managed: true},
+		{Domains: []string{"uploaded.example.com"}, State: "uploaded", Issuer: "Example CA"},
+	}
+	var got []tlsOutput
+	roundTripYAML(t, want, &got)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
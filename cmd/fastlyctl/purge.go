@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/alienth/fastlyctl/fastlyapi"
+	"github.com/alienth/fastlyctl/log"
+	"github.com/alienth/fastlyctl/util"
+	"github.com/alienth/go-fastly"
+	"github.com/urfave/cli"
+)
+
+func purgeURL(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+	urlParam := c.Args().Get(0)
+
+	purge, _, err := fastlyapi.New(client).Purge.URL(urlParam, c.Bool("soft"))
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error purging %s: %s", urlParam, err), -1)
+	}
+	fmt.Printf("Purged %s (purge ID %s)\n", urlParam, purge.ID)
+
+	return nil
+}
+
+func purgeKey(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+	serviceParam := c.Args().Get(0)
+	keyParam := c.Args().Get(1)
+
+	service, err := util.GetService(client, serviceParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	purge, _, err := fastlyapi.New(client).Purge.Key(service.ID, keyParam, c.Bool("soft"))
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error purging key %s: %s", keyParam, err), -1)
+	}
+	fmt.Printf("Purged key %s on service %s (purge ID %s)\n", keyParam, service.Name, purge.ID)
+
+	return nil
+}
+
+// readPurgeKeys reads surrogate keys from file, one per line. Blank lines and
+// lines beginning with # are ignored, as are repeated keys.
+func readPurgeKeys(file string) ([]string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var keys []string
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key := strings.TrimSpace(scanner.Text())
+		if key == "" || strings.HasPrefix(key, "#") || seen[key] {
+			continue
+		}
+		if strings.ContainsAny(key, " \t") {
+			return nil, fmt.Errorf("surrogate key %q contains whitespace", key)
+		}
+		seen[key] = true
+		keys = append(keys, key)
+	}
+	return keys, scanner.Err()
+}
+
+func purgeKeys(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+	serviceParam := c.Args().Get(0)
+	fileParam := c.Args().Get(1)
+
+	keys, err := readPurgeKeys(fileParam)
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error reading %s: %s", fileParam, err), -1)
+	}
+	if len(keys) == 0 {
+		return cli.NewExitError(fmt.Sprintf("No surrogate keys found in %s.", fileParam), -1)
+	}
+
+	service, err := util.GetService(client, serviceParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	var progress *util.Progress
+	if len(keys) > fastlyapi.MaxPurgeKeys {
+		progress = util.NewProgress((len(keys)+fastlyapi.MaxPurgeKeys-1)/fastlyapi.MaxPurgeKeys, true)
+	}
+
+	var purged int
+	var failed []string
+	for i := 0; i < len(keys); i += fastlyapi.MaxPurgeKeys {
+		end := i + fastlyapi.MaxPurgeKeys
+		if end > len(keys) {
+			end = len(keys)
+		}
+		// Carry on with the remaining keys if a batch fails, so that as
+		// much as possible is purged.
+		if _, _, err := fastlyapi.New(client).Purge.Keys(service.ID, keys[i:end], c.Bool("soft")); err != nil {
+			if progress != nil {
+				progress.Finish()
+			}
+			fmt.Fprintf(os.Stderr, "Error purging keys %d to %d: %s\n", i+1, end, err)
+			failed = append(failed, keys[i:end]...)
+			if progress != nil {
+				progress.Done(service.Name, fmt.Sprintf("failed to purge keys %d to %d", i+1, end))
+			}
+			continue
+		}
+		purged += end - i
+		log.Debug(fmt.Sprintf("Purged keys %d to %d of %d\n", i+1, end, len(keys)))
+		if progress != nil {
+			progress.Done(service.Name, fmt.Sprintf("purged keys %d to %d of %d", i+1, end, len(keys)))
+		}
+	}
+	if progress != nil {
+		progress.Finish()
+	}
+	log.Info(fmt.Sprintf("Purged %d of %d keys on service %s\n", purged, len(keys), service.Name))
+
+	if len(failed) > 0 {
+		return cli.NewExitError(fmt.Sprintf("Failed to purge %d keys:\n  %s", len(failed), strings.Join(failed, "\n  ")), -1)
+	}
+	return nil
+}
+
+func purgeAll(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+	serviceParam := c.Args().Get(0)
+
+	service, err := util.GetService(client, serviceParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	if !c.GlobalBool("assume-yes") {
+		proceed, err := util.Prompt(fmt.Sprintf("Purge ALL cached content for service %s?", service.Name))
+		if err != nil {
+			return cli.NewExitError(err.Error(), -1)
+		}
+		if !proceed {
+			return nil
+		}
+	}
+
+	if _, _, err = fastlyapi.New(client).Purge.All(service.ID); err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error purging service %s: %s", service.Name, err), -1)
+	}
+	log.Info(fmt.Sprintf("Purged all content for service %s\n", service.Name))
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestReadPurgeKeys(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		want     []string
+		wantErr  bool
+	}{
+		{"empty", "", nil, false},
+		{"keys", "a\nb\n", []string{"a", "b"}, false},
+		{"no newline", "a\nb", []string{"a", "b"}, false},
+		{"CRLF", "a\r\nb\r\n", []string{"a", "b"}, false},
+		{"blank lines and comments", "\n# keys\na\n\n  \n#b\n", []string{"a"}, false},
+		{"surrounding whitespace", "  a \t\n", []string{"a"}, false},
+		{"repeated", "a\nb\na\n", []string{"a", "b"}, false},
+		{"whitespace in key", "a b\n", nil, true},
+	}
+	for _, test := range tests {
+		f, err := ioutil.TempFile("", "fastlyctl")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.Remove(f.Name())
+		if _, err := f.WriteString(test.contents); err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+
+		keys, err := readPurgeKeys(f.Name())
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("%s: got keys %q, want an error", test.name, keys)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %s", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(keys, test.want) {
+			t.Errorf("%s: got %q, want %q", test.name, keys, test.want)
+		}
+	}
+}
+
+func TestReadPurgeKeysMissingFile(t *testing.T) {
+	if _, err := readPurgeKeys("/nonexistent/fastlyctl-keys"); err == nil {
+		t.Error("got no error for a missing file")
+	}
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/alienth/fastlyctl/format"
+	"github.com/alienth/fastlyctl/log"
+	"github.com/alienth/fastlyctl/util"
+	"github.com/alienth/go-fastly"
+	"github.com/urfave/cli"
+)
+
+// requestSettingActions and requestSettingXFFs hold the values the API
+// accepts for the action and xff fields.
+var requestSettingActions = []string{"lookup", "pass"}
+var requestSettingXFFs = []string{"clear", "leave", "append", "append_all", "overwrite"}
+
+func requestSettingList(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+
+	serviceParam := c.Args().Get(0)
+	service, err := util.GetService(client, serviceParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	activeVersion, err := util.GetActiveVersion(service)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	settings, _, err := client.RequestSetting.List(service.ID, activeVersion)
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Unable to list request settings for service %s: %s", service.Name, err), -1)
+	}
+	if outputFormat(c) != format.Table {
+		output := make([]requestSettingOutput, 0, len(settings))
+		for _, r := range settings {
+			output = append(output, newRequestSettingOutput(r))
+		}
+		return printOutput(c, output)
+	}
+
+	fmt.Printf("Request settings for %s:\n\n", service.Name)
+	fmt.Printf("%-30s %-7s %-10s %-10s %-9s %-5s %-16s %13s\n", "Name", "Action", "XFF", "Force Miss", "Force SSL", "Timer", "Bypass Busy Wait", "Max Stale Age")
+	for _, r := range settings {
+		fmt.Printf("%-30s %-7s %-10s %-10s %-9s %-5s %-16s %13d\n", r.Name, dashIfEmpty(r.Action), dashIfEmpty(r.XFF), util.YesNo(bool(r.ForceMiss)), util.YesNo(bool(r.ForceSSL)), util.YesNo(bool(r.TimerSupport)), util.YesNo(bool(r.BypassBusyWait)), r.MaxStaleAge)
+	}
+	return nil
+}
+
+// checkRequestSetting is used as the Before hook for request-settings add and
+// update.
+func checkRequestSetting(c *cli.Context) error {
+	if c.NArg() < 2 {
+		return cli.NewExitError("Please specify request setting name.", -1)
+	}
+	if c.IsSet("action") && c.String("action") != "" && !util.StringInSlice(c.String("action"), requestSettingActions) {
+		return cli.NewExitError(fmt.Sprintf("Invalid action %q. Must be one of: lookup, pass, or empty for none.", c.String("action")), -1)
+	}
+	if c.IsSet("xff") && !util.StringInSlice(c.String("xff"), requestSettingXFFs) {
+		return cli.NewExitError(fmt.Sprintf("Invalid xff %q. Must be one of: clear, leave, append, append_all, overwrite.", c.String("xff")), -1)
+	}
+	return checkActivate(c)
+}
+
+// applyRequestSettingFlags sets fields on r for each request setting flag
+// which was given.
+func applyRequestSettingFlags(c *cli.Context, r *fastly.RequestSetting) {
+	if c.IsSet("action") {
+		r.Action = c.String("action")
+	}
+	if c.IsSet("xff") {
+		r.XFF = c.String("xff")
+	}
+	if c.IsSet("force-miss") {
+		r.ForceMiss = fastly.Compatibool(c.Bool("force-miss"))
+	}
+	if c.IsSet("force-ssl") {
+		r.ForceSSL = fastly.Compatibool(c.Bool("force-ssl"))
+	}
+	if c.IsSet("timer-support") {
+		r.TimerSupport = fastly.Compatibool(c.Bool("timer-support"))
+	}
+	if c.IsSet("bypass-busy-wait") {
+		r.BypassBusyWait = fastly.Compatibool(c.Bool("bypass-busy-wait"))
+	}
+	if c.IsSet("geo-headers") {
+		r.GeoHeaders = fastly.Compatibool(c.Bool("geo-headers"))
+	}
+	if c.IsSet("max-stale-age") {
+		r.MaxStaleAge = c.Int("max-stale-age")
+	}
+	if c.IsSet("default-host") {
+		r.DefaultHost = c.String("default-host")
+	}
+	if c.IsSet("hash-keys") {
+		r.HashKeys = c.String("hash-keys")
+	}
+	if c.IsSet("request-condition") {
+		r.RequestCondition = c.String("request-condition")
+	}
+}
+
+func requestSettingAdd(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+
+	serviceParam := c.Args().Get(0)
+	nameParam := c.Args().Get(1)
+
+	service, err := util.GetService(client, serviceParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	setting := new(fastly.RequestSetting)
+	setting.Name = nameParam
+	applyRequestSettingFlags(c, setting)
+
+	version, err := draftVersion(c, client, service)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	if _, _, err = client.RequestSetting.Create(service.ID, version, setting); err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error creating request setting: %s", err), -1)
+	}
+	log.Info(fmt.Sprintf("Created request setting %s\n", nameParam))
+
+	if err = finishDraft(c, client, service, version); err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	return nil
+}
+
+func requestSettingUpdate(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+
+	serviceParam := c.Args().Get(0)
+	nameParam := c.Args().Get(1)
+
+	service, err := util.GetService(client, serviceParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	version, err := draftVersion(c, client, service)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	setting, _, err := client.RequestSetting.Get(service.ID, version, nameParam)
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error fetching request setting %s: %s", nameParam, err), -1)
+	}
+	// Zero out read-only fields
+	setting.ServiceID = ""
+	setting.Version = 0
+	applyRequestSettingFlags(c, setting)
+
+	if _, _, err = client.RequestSetting.Update(service.ID, version, nameParam, setting); err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error updating request setting: %s", err), -1)
+	}
+	log.Info(fmt.Sprintf("Updated request setting %s\n", nameParam))
+
+	if err = finishDraft(c, client, service, version); err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	return nil
+}
+
+func requestSettingRemove(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+
+	serviceParam := c.Args().Get(0)
+	nameParam := c.Args().Get(1)
+
+	service, err := util.GetService(client, serviceParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	version, err := draftVersion(c, client, service)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	if _, err = client.RequestSetting.Delete(service.ID, version, nameParam); err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error removing request setting: %s", err), -1)
+	}
+	log.Info(fmt.Sprintf("Removed request setting %s\n", nameParam))
+
+	if err = finishDraft(c, client, service, version); err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	return nil
+}
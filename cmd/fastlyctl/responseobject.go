@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strconv"
+
+	"github.com/alienth/fastlyctl/format"
+	"github.com/alienth/fastlyctl/log"
+	"github.com/alienth/fastlyctl/util"
+	"github.com/alienth/go-fastly"
+	"github.com/urfave/cli"
+)
+
+func responseObjectList(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+
+	serviceParam := c.Args().Get(0)
+	service, err := util.GetService(client, serviceParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	activeVersion, err := util.GetActiveVersion(service)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	responseObjects, _, err := client.ResponseObject.List(service.ID, activeVersion)
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Unable to list response objects for service %s: %s", service.Name, err), -1)
+	}
+	if outputFormat(c) != format.Table {
+		output := make([]responseObjectOutput, 0, len(responseObjects))
+		for _, r := range responseObjects {
+			output = append(output, newResponseObjectOutput(r))
+		}
+		return printOutput(c, output)
+	}
+
+	fmt.Printf("Response objects for %s:\n\n", service.Name)
+	fmt.Printf("%-30s %6s %-20s %-25s %8s  %s\n", "Name", "Status", "Response", "Content Type", "Bytes", "Condition")
+	for _, r := range responseObjects {
+		condition := r.RequestCondition
+		if condition == "" {
+			condition = r.CacheCondition
+		}
+		fmt.Printf("%-30s %6s %-20s %-25s %8d  %s\n", r.Name, r.Status, r.Response, dashIfEmpty(r.ContentType), len(r.Content), dashIfEmpty(condition))
+	}
+	return nil
+}
+
+// checkResponseObjectAdd is used as the Before hook for response-object add.
+func checkResponseObjectAdd(c *cli.Context) error {
+	if c.NArg() < 2 {
+		return cli.NewExitError("Please specify response object name.", -1)
+	}
+	if c.IsSet("content") && c.IsSet("content-file") {
+		return cli.NewExitError("Please specify only one of --content and --content-file.", -1)
+	}
+	if status := c.Int("status"); status < 100 || status > 999 {
+		return cli.NewExitError(fmt.Sprintf("Invalid status code %d.", status), -1)
+	}
+	return checkActivate(c)
+}
+
+func responseObjectAdd(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+
+	serviceParam := c.Args().Get(0)
+	nameParam := c.Args().Get(1)
+
+	content := c.String("content")
+	if file := c.String("content-file"); file != "" {
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			return cli.NewExitError(fmt.Sprintf("Error reading %s: %s", file, err), -1)
+		}
+		content = string(b)
+	}
+
+	service, err := util.GetService(client, serviceParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	responseObject := new(fastly.ResponseObject)
+	responseObject.Name = nameParam
+	responseObject.Status = strconv.Itoa(c.Int("status"))
+	responseObject.Response = c.String("response")
+	responseObject.Content = content
+	responseObject.ContentType = c.String("content-type")
+	responseObject.RequestCondition = c.String("request-condition")
+	responseObject.CacheCondition = c.String("cache-condition")
+
+	version, err := draftVersion(c, client, service)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	if _, _, err = client.ResponseObject.Create(service.ID, version, responseObject); err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error creating response object: %s", err), -1)
+	}
+	log.Info(fmt.Sprintf("Created response object %s\n", nameParam))
+
+	if err = finishDraft(c, client, service, version); err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	return nil
+}
+
+func responseObjectRemove(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+
+	serviceParam := c.Args().Get(0)
+	nameParam := c.Args().Get(1)
+
+	service, err := util.GetService(client, serviceParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	version, err := draftVersion(c, client, service)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	if _, err = client.ResponseObject.Delete(service.ID, version, nameParam); err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error removing response object: %s", err), -1)
+	}
+	log.Info(fmt.Sprintf("Removed response object %s\n", nameParam))
+
+	if err = finishDraft(c, client, service, version); err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	return nil
+}
@@ -2,22 +2,212 @@ package main
 
 import (
 	"fmt"
+	"os"
+	"regexp"
+	"strings"
 
+	"github.com/alienth/fastlyctl/fastlyapi"
+	"github.com/alienth/fastlyctl/format"
+	"github.com/alienth/fastlyctl/log"
+	"github.com/alienth/fastlyctl/util"
 	"github.com/alienth/go-fastly"
 	"github.com/urfave/cli"
 )
 
+// serviceType returns the type of a service, either vcl or wasm. Services
+// created before Compute@Edge have no type, and are VCL services.
+func serviceType(s *fastlyapi.Service) string {
+	if s.Type == "" {
+		return "vcl"
+	}
+	return s.Type
+}
+
 func serviceList(c *cli.Context) error {
 	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
 
-	services, _, err := client.Service.List()
+	all, _, err := fastlyapi.New(client).Service.List()
 	if err != nil {
 		return cli.NewExitError(fmt.Sprintf("Error listing services: %s", err), -1)
 	}
-	fmt.Printf("%25s %8s  %s\n", "ID", "Version", "Name")
+	var services []*fastlyapi.Service
+	for _, s := range all {
+		if !c.IsSet("type") || serviceType(s) == c.String("type") {
+			services = append(services, s)
+		}
+	}
+
+	return printServices(c, services)
+}
+
+// printServices prints services in the selected output format.
+func printServices(c *cli.Context, services []*fastlyapi.Service) error {
+	if outputFormat(c) != format.Table {
+		output := make([]serviceOutput, 0, len(services))
+		for _, s := range services {
+			output = append(output, newServiceOutput(s))
+		}
+		return printOutput(c, output)
+	}
+
+	fmt.Printf("%25s %-5s %8s %8s  %s\n", "ID", "Type", "Version", "Active", "Name")
 	for _, s := range services {
-		fmt.Printf("%25s %8d  %s\n", s.ID, s.Version, s.Name)
+		active := "-"
+		if activeVersion, err := util.GetActiveVersion(&s.Service); err == nil {
+			active = fmt.Sprintf("%d", activeVersion)
+		}
+		fmt.Printf("%25s %-5s %8d %8s  %s\n", s.ID, serviceType(s), s.Version, active, s.Name)
+	}
+
+	return nil
+}
+
+func serviceCreate(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+	name := c.Args().Get(0)
+
+	if _, err := util.GetServiceByName(client, name); err == nil {
+		return cli.NewExitError(fmt.Sprintf("Service %s already exists.", name), -1)
+	} else if !util.IsServiceNotFound(err) {
+		return cli.NewExitError(fmt.Sprintf("Unable to check whether service %s exists: %s", name, err), -1)
+	}
+
+	if !c.GlobalBool("assume-yes") {
+		proceed, err := util.Prompt(fmt.Sprintf("Create service %s?", name))
+		if err != nil {
+			return cli.NewExitError(err.Error(), -1)
+		}
+		if !proceed {
+			return nil
+		}
+	}
+
+	if util.DryRun() {
+		fmt.Printf("Dry run: would create service %s.\n", name)
+		return nil
 	}
 
+	service := new(fastly.Service)
+	service.Name = name
+	service.Comment = c.String("comment")
+
+	service, _, err := client.Service.Create(service)
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error creating service: %s", err), -1)
+	}
+	fmt.Printf("Created service %s with ID %s\n", service.Name, service.ID)
+
 	return nil
 }
+
+func serviceRename(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+	serviceParam := c.Args().Get(0)
+	name := c.Args().Get(1)
+
+	service, err := util.GetService(client, serviceParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	if existing, err := util.GetServiceByName(client, name); err == nil {
+		if existing.ID != service.ID {
+			return cli.NewExitError(fmt.Sprintf("Service %s already exists with ID %s.", name, existing.ID), -1)
+		}
+	} else if !util.IsServiceNotFound(err) {
+		return cli.NewExitError(fmt.Sprintf("Unable to check whether service %s exists: %s", name, err), -1)
+	}
+
+	if !c.GlobalBool("assume-yes") {
+		proceed, err := util.Prompt(fmt.Sprintf("Rename service %s to %s?", service.Name, name))
+		if err != nil {
+			return cli.NewExitError(err.Error(), -1)
+		}
+		if !proceed {
+			return nil
+		}
+	}
+
+	// The comment is always sent with an update, so keep the current one
+	// unless a new one was given.
+	update := &fastly.Service{Name: name, Comment: service.Comment}
+	if c.IsSet("comment") {
+		update.Comment = c.String("comment")
+	}
+	updated, _, err := client.Service.Update(service.ID, update)
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error renaming service: %s", err), -1)
+	}
+	util.ForgetService(service)
+	log.Info(fmt.Sprintf("Renamed service %s to %s\n", service.Name, updated.Name))
+	fmt.Fprintf(os.Stderr, "Warning: references to %s in your config must be updated to %s.\n", service.Name, updated.Name)
+
+	return nil
+}
+
+func serviceDelete(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+	serviceParam := c.Args().Get(0)
+
+	service, err := util.GetService(client, serviceParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	activeVersion, err := util.GetActiveVersion(service)
+	active := "none"
+	if err == nil {
+		active = fmt.Sprintf("%d", activeVersion)
+	}
+	fmt.Printf("Service: %s (%s)\nActive version: %s\n", service.Name, service.ID, active)
+
+	if !c.GlobalBool("assume-yes") {
+		proceed, err := util.Prompt(fmt.Sprintf("Delete service %s? THIS CANNOT BE UNDONE.", service.Name))
+		if err != nil {
+			return cli.NewExitError(err.Error(), -1)
+		}
+		if !proceed {
+			return nil
+		}
+	}
+
+	resp, err := client.Service.Delete(service.ID)
+	if err != nil {
+		if resp != nil && resp.StatusCode == 400 && active != "none" {
+			return cli.NewExitError(fmt.Sprintf("Error deleting service: %s\nService %s has active version %s. Deactivate it before deleting the service.", err, service.Name, active), -1)
+		}
+		return cli.NewExitError(fmt.Sprintf("Error deleting service: %s", err), -1)
+	}
+	util.ForgetService(service)
+	log.Info(fmt.Sprintf("Deleted service %s\n", service.Name))
+
+	return nil
+}
+
+func serviceSearch(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+	query := c.Args().Get(0)
+
+	match := func(name string) bool {
+		return strings.Contains(strings.ToLower(name), strings.ToLower(query))
+	}
+	if c.Bool("regex") {
+		re, err := regexp.Compile(query)
+		if err != nil {
+			return cli.NewExitError(fmt.Sprintf("Invalid regular expression: %s", err), -1)
+		}
+		match = re.MatchString
+	}
+
+	services, _, err := fastlyapi.New(client).Service.List()
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error listing services: %s", err), -1)
+	}
+	var matches []*fastlyapi.Service
+	for _, s := range services {
+		if match(s.Name) {
+			matches = append(matches, s)
+		}
+	}
+
+	return printServices(c, matches)
+}
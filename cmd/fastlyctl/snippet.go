@@ -0,0 +1,189 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/alienth/fastlyctl/fastlyapi"
+	"github.com/alienth/fastlyctl/format"
+	"github.com/alienth/fastlyctl/log"
+	"github.com/alienth/fastlyctl/util"
+	"github.com/alienth/go-fastly"
+	"github.com/urfave/cli"
+)
+
+func snippetList(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+
+	serviceParam := c.Args().Get(0)
+	service, err := util.GetService(client, serviceParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	activeVersion, err := util.GetActiveVersion(service)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	snippets, _, err := fastlyapi.New(client).Snippet.List(service.ID, activeVersion)
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Unable to list snippets for service %s: %s", service.Name, err), -1)
+	}
+	if outputFormat(c) != format.Table {
+		output := make([]snippetOutput, 0, len(snippets))
+		for _, s := range snippets {
+			output = append(output, newSnippetOutput(s))
+		}
+		return printOutput(c, output)
+	}
+
+	fmt.Printf("Snippets for %s:\n\n", service.Name)
+	fmt.Printf("%-30s %-8s %8s  %s\n", "Name", "Type", "Priority", "Dynamic")
+	for _, s := range snippets {
+		fmt.Printf("%-30s %-8s %8d  %s\n", s.Name, s.Type, s.Priority, util.YesNo(s.Dynamic == 1))
+	}
+	return nil
+}
+
+func snippetGet(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+
+	serviceParam := c.Args().Get(0)
+	nameParam := c.Args().Get(1)
+
+	service, err := util.GetService(client, serviceParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	activeVersion, err := util.GetActiveVersion(service)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	snippet, _, err := fastlyapi.New(client).Snippet.Get(service.ID, activeVersion, nameParam)
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error fetching snippet %s: %s", nameParam, err), -1)
+	}
+	content := snippet.Content
+	if snippet.Dynamic == 1 {
+		dynamic, _, err := fastlyapi.New(client).Snippet.GetDynamic(service.ID, snippet.ID)
+		if err != nil {
+			return cli.NewExitError(fmt.Sprintf("Error fetching content of dynamic snippet %s: %s", nameParam, err), -1)
+		}
+		content = dynamic.Content
+	}
+	fmt.Print(content)
+
+	return nil
+}
+
+// checkSnippetType is used by the Before hook of snippet upload.
+func checkSnippetType(c *cli.Context) error {
+	if !c.IsSet("type") {
+		return nil
+	}
+	var types []string
+	for _, t := range fastlyapi.SnippetTypes {
+		if c.String("type") == string(t) {
+			return nil
+		}
+		types = append(types, string(t))
+	}
+	return cli.NewExitError(fmt.Sprintf("Invalid snippet type %q. Must be one of: %s", c.String("type"), strings.Join(types, ", ")), -1)
+}
+
+func snippetUpload(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+	api := fastlyapi.New(client)
+
+	serviceParam := c.Args().Get(0)
+	nameParam := c.Args().Get(1)
+	fileParam := c.Args().Get(2)
+
+	content, err := ioutil.ReadFile(fileParam)
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error reading %s: %s", fileParam, err), -1)
+	}
+
+	service, err := util.GetService(client, serviceParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	activeVersion, err := util.GetActiveVersion(service)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	// Dynamic snippets which already exist are updated in place, without a
+	// new version. Changing any other attribute requires a draft.
+	existing, _, err := api.Snippet.Get(service.ID, activeVersion, nameParam)
+	if err == nil && existing.Dynamic == 1 && !c.IsSet("version") && !c.IsSet("type") && !c.IsSet("priority") && !c.IsSet("dynamic") {
+		if _, _, err = api.Snippet.UpdateDynamic(service.ID, existing.ID, string(content)); err != nil {
+			return cli.NewExitError(fmt.Sprintf("Error updating dynamic snippet: %s", err), -1)
+		}
+		log.Info(fmt.Sprintf("Updated dynamic snippet %s on service %s. The change is live.\n", nameParam, service.Name))
+		return nil
+	}
+
+	// Check the flags against the snippet before a draft is made, so that
+	// a mistake does not leave an empty draft behind. A new draft is cloned
+	// from the active version, so it has the same snippets.
+	base := activeVersion
+	if c.IsSet("version") {
+		base = uint(c.Int("version"))
+	}
+	current, _, err := api.Snippet.Get(service.ID, base, nameParam)
+	exists := err == nil
+	if exists && c.IsSet("dynamic") && c.Bool("dynamic") != (current.Dynamic == 1) {
+		return cli.NewExitError(fmt.Sprintf("Snippet %s already exists. A snippet cannot be changed between dynamic and versioned.", nameParam), -1)
+	}
+	if !exists && !c.IsSet("type") {
+		return cli.NewExitError("Please specify --type for a new snippet.", -1)
+	}
+
+	version, err := draftVersion(c, client, service)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	snippet := new(fastlyapi.Snippet)
+	snippet.Name = nameParam
+	snippet.Content = string(content)
+	if c.IsSet("type") {
+		snippet.Type = fastlyapi.SnippetType(c.String("type"))
+	}
+	if c.IsSet("priority") {
+		snippet.Priority = uint(c.Int("priority"))
+	}
+
+	if exists {
+		if current.Dynamic == 1 {
+			// The content of a dynamic snippet is not part of the
+			// version, so it is set separately.
+			snippet.Content = ""
+		}
+		if _, _, err = api.Snippet.Update(service.ID, version, nameParam, snippet); err != nil {
+			return cli.NewExitError(fmt.Sprintf("Error updating snippet: %s", err), -1)
+		}
+		if current.Dynamic == 1 {
+			if _, _, err = api.Snippet.UpdateDynamic(service.ID, current.ID, string(content)); err != nil {
+				return cli.NewExitError(fmt.Sprintf("Error updating dynamic snippet: %s", err), -1)
+			}
+		}
+		log.Info(fmt.Sprintf("Updated snippet %s\n", nameParam))
+	} else {
+		if c.Bool("dynamic") {
+			snippet.Dynamic = 1
+		}
+		if _, _, err = api.Snippet.Create(service.ID, version, snippet); err != nil {
+			return cli.NewExitError(fmt.Sprintf("Error creating snippet: %s", err), -1)
+		}
+		log.Info(fmt.Sprintf("Created snippet %s\n", nameParam))
+	}
+
+	if err = finishDraft(c, client, service, version); err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	return nil
+}
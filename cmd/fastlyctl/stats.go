@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alienth/fastlyctl/fastlyapi"
+	"github.com/alienth/fastlyctl/format"
+	"github.com/alienth/fastlyctl/util"
+	"github.com/alienth/go-fastly"
+	"github.com/urfave/cli"
+)
+
+// statsMetrics lists the metrics the stats command reports, in display order.
+var statsMetrics = []struct {
+	Name  string
+	Value func(*fastlyapi.Stats) uint64
+}{
+	{"requests", func(s *fastlyapi.Stats) uint64 { return s.Requests }},
+	{"hits", func(s *fastlyapi.Stats) uint64 { return s.Hits }},
+	{"miss", func(s *fastlyapi.Stats) uint64 { return s.Miss }},
+	{"pass", func(s *fastlyapi.Stats) uint64 { return s.Pass }},
+	{"synth", func(s *fastlyapi.Stats) uint64 { return s.Synth }},
+	{"errors", func(s *fastlyapi.Stats) uint64 { return s.Errors }},
+	{"bandwidth", func(s *fastlyapi.Stats) uint64 { return s.Bandwidth }},
+	{"status_1xx", func(s *fastlyapi.Stats) uint64 { return s.Status1xx }},
+	{"status_2xx", func(s *fastlyapi.Stats) uint64 { return s.Status2xx }},
+	{"status_3xx", func(s *fastlyapi.Stats) uint64 { return s.Status3xx }},
+	{"status_4xx", func(s *fastlyapi.Stats) uint64 { return s.Status4xx }},
+	{"status_5xx", func(s *fastlyapi.Stats) uint64 { return s.Status5xx }},
+}
+
+func statsMetricNames() []string {
+	names := make([]string, 0, len(statsMetrics))
+	for _, m := range statsMetrics {
+		names = append(names, m.Name)
+	}
+	return names
+}
+
+// checkStats is used as the Before hook for the stats command.
+func checkStats(c *cli.Context) error {
+	if err := checkOutputFormat(c); err != nil {
+		return err
+	}
+	if !c.Args().Present() {
+		return cli.NewExitError("Please specify service.", -1)
+	}
+	if c.Bool("realtime") && (c.IsSet("from") || c.IsSet("to") || c.IsSet("by")) {
+		return cli.NewExitError("--realtime cannot be combined with --from, --to or --by.", -1)
+	}
+	known := make(map[string]bool)
+	for _, name := range statsMetricNames() {
+		known[name] = true
+	}
+	for _, field := range c.StringSlice("field") {
+		if !known[field] {
+			return cli.NewExitError(fmt.Sprintf("Unknown field %q. Must be one of: %s", field, strings.Join(statsMetricNames(), ", ")), -1)
+		}
+	}
+	return nil
+}
+
+func stats(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+
+	service, err := util.GetService(client, c.Args().Get(0))
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	var periods []*fastlyapi.Stats
+	if c.Bool("realtime") {
+		periods, _, err = fastlyapi.New(client).Stats.Realtime(service.ID)
+	} else {
+		periods, _, err = fastlyapi.New(client).Stats.Service(service.ID, c.String("from"), c.String("to"), c.String("by"))
+	}
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Unable to fetch stats for service %s: %s", service.Name, err), -1)
+	}
+
+	fields := make(map[string]bool)
+	for _, field := range c.StringSlice("field") {
+		fields[field] = true
+	}
+	output := make([]statOutput, 0, len(statsMetrics))
+	for _, m := range statsMetrics {
+		if len(fields) > 0 && !fields[m.Name] {
+			continue
+		}
+		var total uint64
+		for _, p := range periods {
+			total += m.Value(p)
+		}
+		output = append(output, statOutput{Metric: m.Name, Value: total})
+	}
+	if outputFormat(c) != format.Table {
+		return printOutput(c, output)
+	}
+
+	if c.Bool("realtime") {
+		fmt.Printf("Stats for %s over the last %d seconds:\n\n", service.Name, len(periods))
+	} else {
+		fmt.Printf("Stats for %s over %d periods:\n\n", service.Name, len(periods))
+	}
+	fmt.Printf("%-15s %s\n", "Metric", "Value")
+	for _, o := range output {
+		fmt.Printf("%-15s %d\n", o.Metric, o.Value)
+	}
+	return nil
+}
@@ -6,20 +6,43 @@ import (
 	"io/ioutil"
 	"net"
 	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/BurntSushi/toml"
 	"github.com/alienth/fastlyctl/_version"
+	"github.com/alienth/fastlyctl/fastlyapi"
+	"github.com/alienth/fastlyctl/format"
 	"github.com/alienth/fastlyctl/log"
 	"github.com/alienth/fastlyctl/util"
 	"github.com/alienth/go-fastly"
 	"github.com/imdario/mergo"
 	"github.com/urfave/cli"
+	"gopkg.in/yaml.v2"
 )
 
 var pendingVersions map[string]fastly.Version
+
+// createdVersions records the services for which the pending version was
+// cloned during this run, rather than reused from an earlier push.
+var createdVersions map[string]bool
+var pendingMu sync.Mutex
+
+// cloneBase is the version new versions are cloned from, as given with
+// --from-version. Zero means the active version.
+var cloneBase uint
+
+// forceClone disables the reuse of drafts left behind by earlier pushes.
+var forceClone bool
 var siteConfigs map[string]SiteConfig
 
+// unknownConfigKeys holds the location of any keys in the config which did not
+// map to a known field.
+var unknownConfigKeys []string
+
 const (
 	defaultHealthCheckHTTPVersion = "1.1"
 	defaultS3TimestampFormat      = "%Y-%m-%dT%H:%M:%S.000"
@@ -40,7 +63,7 @@ type SiteConfig struct {
 	Syslogs         []fastly.Syslog
 	Gzips           []fastly.Gzip
 	HealthChecks    []fastly.HealthCheck
-	Dictionaries    []fastly.Dictionary
+	Dictionaries    []fastlyapi.Dictionary
 	ACLs            []fastly.ACL
 	VCLs            []VCL
 	RequestSettings []fastly.RequestSetting
@@ -61,20 +84,22 @@ type VCL struct {
 }
 
 func readConfig(file string) error {
-	body, err := ioutil.ReadFile(file)
+	files, err := util.ConfigFiles(file)
 	if err != nil {
 		return err
 	}
-	if strings.HasSuffix(file, ".toml") {
-		if err := toml.Unmarshal(body, &siteConfigs); err != nil {
-			return fmt.Errorf("toml parsing error: %s\n", err)
-		}
-	} else if strings.HasSuffix(file, ".json") {
-		if err := json.Unmarshal(body, &siteConfigs); err != nil {
-			return fmt.Errorf("json parsing error: %s\n", err)
+
+	siteConfigs = make(map[string]SiteConfig)
+	definedConfigFields = make(map[string][]string)
+	unknownConfigKeys = nil
+	loader := configLoader{sources: make(map[string]string), loaded: make(map[string]bool)}
+	for _, f := range files {
+		if err := loader.load(f); err != nil {
+			return err
 		}
-	} else {
-		return fmt.Errorf("Unknown config file type for file %s\n", file)
+	}
+	if len(loader.duplicates) > 0 {
+		return fmt.Errorf("duplicate service definitions:\n  %s\n", strings.Join(loader.duplicates, "\n  "))
 	}
 
 	//outfile, _ := os.OpenFile("out.toml", os.O_CREATE|os.O_RDWR, 0644)
@@ -86,44 +111,381 @@ func readConfig(file string) error {
 	//jencoder.Encode(&siteConfigs)
 	//outfile.Close()
 
+	return applyDefaults()
+}
+
+// definedConfigFields holds the fields set by each service in the config, as
+// paths of field names such as Settings.DefaultTTL.
+var definedConfigFields map[string][]string
+
+// applyDefaults merges the _default_ service into every other service. Values
+// set on a service win over the defaults, even empty ones such as 0 or "",
+// nested tables are merged key by key, and a list set on a service replaces
+// the default list entirely.
+func applyDefaults() error {
+	defaults, ok := siteConfigs["_default_"]
+	if !ok {
+		return nil
+	}
 	for name, config := range siteConfigs {
 		if name == "_default_" {
 			continue
 		}
 
-		if err := mergo.Merge(&config, siteConfigs["_default_"]); err != nil {
+		// Each service gets its own copy of the defaults, as tokens such
+		// as _servicename_ are later replaced in place.
+		inherited := defaults
+		copyValue(reflect.ValueOf(&inherited).Elem())
+		merged := config
+		if err := mergo.Merge(&merged, inherited); err != nil {
 			return err
 		}
-		siteConfigs[name] = config
+		// mergo treats empty values as unset, so those set on the
+		// service are put back.
+		for _, path := range definedConfigFields[name] {
+			dst, src := reflect.ValueOf(&merged).Elem(), reflect.ValueOf(config)
+			for _, field := range strings.Split(path, ".") {
+				dst, src = dst.FieldByName(field), src.FieldByName(field)
+			}
+			dst.Set(src)
+		}
+		siteConfigs[name] = merged
+	}
+	return nil
+}
+
+// copyValue replaces the slices, maps and pointers reachable from v with
+// copies, so that v shares no memory with the value it was copied from.
+func copyValue(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Field(i).CanSet() {
+				copyValue(v.Field(i))
+			}
+		}
+	case reflect.Slice:
+		if v.IsNil() {
+			return
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		reflect.Copy(c, v)
+		for i := 0; i < c.Len(); i++ {
+			copyValue(c.Index(i))
+		}
+		v.Set(c)
+	case reflect.Map:
+		if v.IsNil() {
+			return
+		}
+		c := reflect.MakeMap(v.Type())
+		for _, key := range v.MapKeys() {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(v.MapIndex(key))
+			copyValue(elem)
+			c.SetMapIndex(key, elem)
+		}
+		v.Set(c)
+	case reflect.Ptr:
+		if v.IsNil() {
+			return
+		}
+		c := reflect.New(v.Type().Elem())
+		c.Elem().Set(v.Elem())
+		copyValue(c.Elem())
+		v.Set(c)
+	}
+}
+
+// configLoader reads config files into siteConfigs, following any include
+// directives and recording which file each service was defined in.
+type configLoader struct {
+	sources    map[string]string
+	loaded     map[string]bool
+	duplicates []string
+}
+
+// load reads a single config file, followed by the files matched by its
+// include patterns in sorted order. Include patterns are relative to the
+// directory of the file containing them, or to CWD for a config read from
+// stdin.
+func (l *configLoader) load(file string) error {
+	if abs, err := filepath.Abs(file); err == nil {
+		if l.loaded[abs] {
+			return nil
+		}
+		l.loaded[abs] = true
+	}
+
+	body, err := util.ReadConfigFile(file)
+	if err != nil {
+		return err
+	}
+
+	decode, ok := configDecoders[util.ConfigExt(file)]
+	if !ok {
+		return fmt.Errorf("Unknown config file type for file %s\n", file)
+	}
+	include, configs, defined, err := decode(file, body)
+	if err != nil {
+		return err
+	}
+
+	// Environment variables are interpolated before anything else looks at
+	// the config, so that service names may reference them too.
+	interpolated := make(map[string]SiteConfig, len(configs))
+	fields := make(map[string][]string, len(configs))
+	var names []string
+	for name, config := range configs {
+		raw := name
+		if name, err = interpolateEnv(name); err != nil {
+			return fmt.Errorf("%s: %s\n", file, err)
+		}
+		if err := interpolateConfig(&config); err != nil {
+			return fmt.Errorf("%s: service %s: %s\n", file, name, err)
+		}
+		// defaults is accepted as a friendlier name for _default_.
+		if name == "defaults" {
+			if _, ok := configs["_default_"]; ok {
+				return fmt.Errorf("%s: only one of defaults and _default_ may be defined\n", file)
+			}
+			name = "_default_"
+		}
+		fields[name] = defined[raw]
+		interpolated[name] = config
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if source, ok := l.sources[name]; ok {
+			l.duplicates = append(l.duplicates, fmt.Sprintf("%s is defined in both %s and %s", name, source, file))
+			continue
+		}
+		l.sources[name] = file
+		siteConfigs[name] = interpolated[name]
+		definedConfigFields[name] = fields[name]
+	}
+
+	for _, pattern := range include {
+		if pattern, err = interpolateEnv(pattern); err != nil {
+			return fmt.Errorf("%s: %s\n", file, err)
+		}
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(filepath.Dir(file), pattern)
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid include pattern %s in %s: %s\n", pattern, file, err)
+		}
+		sort.Strings(matches)
+		for _, match := range matches {
+			if err := l.load(match); err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
 }
 
+// configDecoders maps config file extensions to the decoder for that format.
+// Each decoder returns the include patterns and service configs defined in a
+// file, along with the fields set by each service.
+var configDecoders = map[string]func(file string, body []byte) ([]string, map[string]SiteConfig, map[string][]string, error){
+	".toml": decodeTOMLConfig,
+	".json": decodeJSONConfig,
+	".yaml": decodeYAMLConfig,
+	".yml":  decodeYAMLConfig,
+}
+
+func decodeTOMLConfig(file string, body []byte) ([]string, map[string]SiteConfig, map[string][]string, error) {
+	var include []string
+	configs := make(map[string]SiteConfig)
+	var raw map[string]toml.Primitive
+	md, err := toml.Decode(string(body), &raw)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("toml parsing error in %s: %s\n", file, err)
+	}
+	for name, prim := range raw {
+		var err error
+		switch name {
+		case "include":
+			err = md.PrimitiveDecode(prim, &include)
+		case "profiles":
+			// The profiles table holds API keys rather than service config.
+		default:
+			var config SiteConfig
+			err = md.PrimitiveDecode(prim, &config)
+			configs[name] = config
+		}
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("toml parsing error in %s: %s\n", file, err)
+		}
+	}
+	recordUnknownKeys(file, body, undecodedTOMLKeys(md))
+	return include, configs, definedTOMLFields(md), nil
+}
+
+func decodeJSONConfig(file string, body []byte) ([]string, map[string]SiteConfig, map[string][]string, error) {
+	return decodeJSONSource(file, body, body)
+}
+
+// decodeJSONSource decodes a JSON config converted from source, in which the
+// lines of any unknown keys are looked up.
+func decodeJSONSource(file string, body, source []byte) ([]string, map[string]SiteConfig, map[string][]string, error) {
+	var include []string
+	configs := make(map[string]SiteConfig)
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, nil, nil, fmt.Errorf("json parsing error in %s: %s\n", file, err)
+	}
+	var unknown []string
+	defined := make(map[string][]string)
+	for name, msg := range raw {
+		var err error
+		switch name {
+		case "include":
+			err = json.Unmarshal(msg, &include)
+		case "profiles":
+		default:
+			var config SiteConfig
+			if err = json.Unmarshal(msg, &config); err != nil {
+				break
+			}
+			configs[name] = config
+			var decoded interface{}
+			if err = json.Unmarshal(msg, &decoded); err != nil {
+				break
+			}
+			unknown = append(unknown, unknownJSONKeys(decoded, reflect.TypeOf(config), name)...)
+			defined[name] = definedJSONFields(decoded, reflect.TypeOf(config), "")
+		}
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("json parsing error in %s: %s\n", file, err)
+		}
+	}
+	recordUnknownKeys(file, source, unknown)
+	return include, configs, defined, nil
+}
+
+// decodeYAMLConfig converts a YAML config into JSON before decoding it, so
+// YAML configs use the same field names as JSON configs.
+func decodeYAMLConfig(file string, body []byte) ([]string, map[string]SiteConfig, map[string][]string, error) {
+	var raw interface{}
+	if err := yaml.Unmarshal(body, &raw); err != nil {
+		return nil, nil, nil, fmt.Errorf("yaml parsing error in %s: %s\n", file, err)
+	}
+	if raw == nil {
+		return nil, nil, nil, nil
+	}
+	converted, err := yamlToJSON(raw)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("yaml parsing error in %s: %s\n", file, err)
+	}
+	jsonBody, err := json.Marshal(converted)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("yaml parsing error in %s: %s\n", file, err)
+	}
+	return decodeJSONSource(file, jsonBody, body)
+}
+
+// yamlToJSON converts the maps produced by the YAML decoder, which may have
+// non-string keys, into maps which can be encoded as JSON.
+func yamlToJSON(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			k, ok := key.(string)
+			if !ok {
+				return nil, fmt.Errorf("non-string key %v", key)
+			}
+			converted, err := yamlToJSON(value)
+			if err != nil {
+				return nil, err
+			}
+			m[k] = converted
+		}
+		return m, nil
+	case []interface{}:
+		for i, value := range v {
+			converted, err := yamlToJSON(value)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = converted
+		}
+		return v, nil
+	}
+	return v, nil
+}
+
 var versionComment = "fastlyctl-" + version.FullVersion()
 
+// getPendingVersion, setPendingVersion, and deletePendingVersion guard access
+// to pendingVersions, as services may be synced concurrently.
+func getPendingVersion(serviceID string) (fastly.Version, bool) {
+	pendingMu.Lock()
+	defer pendingMu.Unlock()
+	version, ok := pendingVersions[serviceID]
+	return version, ok
+}
+
+func setPendingVersion(serviceID string, version fastly.Version) {
+	pendingMu.Lock()
+	defer pendingMu.Unlock()
+	pendingVersions[serviceID] = version
+}
+
+func deletePendingVersion(serviceID string) {
+	pendingMu.Lock()
+	defer pendingMu.Unlock()
+	delete(pendingVersions, serviceID)
+}
+
+func versionCreated(serviceID string) bool {
+	pendingMu.Lock()
+	defer pendingMu.Unlock()
+	return createdVersions[serviceID]
+}
+
+// reportDraft notes a pending version which was left unactivated. Fastly does
+// not allow versions to be deleted, so the best we can do is say which version
+// was left behind. It will be reused by the next push.
+func reportDraft(s *fastly.Service, version uint) {
+	fmt.Printf("Draft version %d for service %s was left unactivated. It will be reused by the next push.\n", version, s.Name)
+}
+
 func prepareNewVersion(client *fastly.Client, s *fastly.Service) (fastly.Version, error) {
 	// See if we've already prepared a version
-	if version, ok := pendingVersions[s.ID]; ok {
+	if version, ok := getPendingVersion(s.ID); ok {
 		return version, nil
 	}
 
-	// Look for an inactive version higher than our current version
-	versions, _, err := client.Version.List(s.ID)
-	if err != nil {
-		return fastly.Version{}, err
-	}
-	for _, v := range versions {
-		if v.Number > s.Version && v.Comment == versionComment && !v.Active && !v.Locked {
-			pendingVersions[s.ID] = *v
-			return *v, nil
+	// Look for an inactive version higher than our current version. Such a
+	// draft was cloned from the active version, so it is not reused when
+	// another base was asked for.
+	if !forceClone && cloneBase == 0 {
+		versions, _, err := client.Version.List(s.ID)
+		if err != nil {
+			return fastly.Version{}, err
+		}
+		for _, v := range versions {
+			if v.Number > s.Version && v.Comment == versionComment && !v.Active && !v.Locked {
+				setPendingVersion(s.ID, *v)
+				return *v, nil
+			}
 		}
 	}
 
 	// Otherwise, create a new version
-	newversion, _, err := client.Version.Clone(s.ID, s.Version)
+	base := s.Version
+	if cloneBase != 0 {
+		base = cloneBase
+	}
+	newversion, _, err := client.Version.Clone(s.ID, base)
 	if err != nil {
-		return *newversion, err
+		return fastly.Version{}, fmt.Errorf("Error cloning version %d of service %s: %s", base, s.Name, err)
 	}
 	newversion.Comment = versionComment
 	// Zero out unwritable fields
@@ -132,16 +494,15 @@ func prepareNewVersion(client *fastly.Client, s *fastly.Service) (fastly.Version
 	if _, _, err := client.Version.Update(s.ID, newversion.Number, newversion); err != nil {
 		return *newversion, err
 	}
-	pendingVersions[s.ID] = *newversion
+	setPendingVersion(s.ID, *newversion)
+	pendingMu.Lock()
+	createdVersions[s.ID] = true
+	pendingMu.Unlock()
 	return *newversion, nil
 }
 
-func syncVCLs(client *fastly.Client, s *fastly.Service, vcls []VCL) error {
-	newversion, err := prepareNewVersion(client, s)
-	if err != nil {
-		return err
-	}
-
+// loadVCLs reads the content of each configured VCL.
+func loadVCLs(vcls []VCL) ([]fastly.VCL, error) {
 	var newVCLs []fastly.VCL
 
 	for _, vcl := range vcls {
@@ -150,23 +511,36 @@ func syncVCLs(client *fastly.Client, s *fastly.Service, vcls []VCL) error {
 		}
 		var newVCL fastly.VCL
 		if vcl.File != "" && vcl.Content != "" {
-			return fmt.Errorf("Cannot specify both a File and Content for VCL %s", vcl.Name)
+			return nil, fmt.Errorf("Cannot specify both a File and Content for VCL %s", vcl.Name)
 		}
 		if vcl.File != "" {
-			var content []byte
-			if content, err = ioutil.ReadFile(vcl.File); err != nil {
-				return err
+			content, err := ioutil.ReadFile(vcl.File)
+			if err != nil {
+				return nil, err
 			}
 			newVCL.Content = string(content)
 		} else if vcl.Content != "" {
 			newVCL.Content = vcl.Content
 		} else {
-			return fmt.Errorf("No Content or File specified for VCL %s", vcl.Name)
+			return nil, fmt.Errorf("No Content or File specified for VCL %s", vcl.Name)
 		}
 		newVCL.Main = vcl.Main
 		newVCL.Name = vcl.Name
 		newVCLs = append(newVCLs, newVCL)
 	}
+	return newVCLs, nil
+}
+
+func syncVCLs(client *fastly.Client, s *fastly.Service, vcls []VCL) error {
+	newversion, err := prepareNewVersion(client, s)
+	if err != nil {
+		return err
+	}
+
+	newVCLs, err := loadVCLs(vcls)
+	if err != nil {
+		return err
+	}
 
 	existingVCLs, _, err := client.VCL.List(s.ID, newversion.Number)
 	if err != nil {
@@ -212,17 +586,23 @@ func syncVCLs(client *fastly.Client, s *fastly.Service, vcls []VCL) error {
 	return nil
 }
 
+// prepareHealthChecks fills in defaults the API applies to health checks, so
+// they compare equal to those returned by the API.
+func prepareHealthChecks(healthChecks []fastly.HealthCheck) {
+	for i := range healthChecks {
+		if healthChecks[i].HTTPVersion == "" {
+			healthChecks[i].HTTPVersion = defaultHealthCheckHTTPVersion
+		}
+	}
+}
+
 func syncHealthChecks(client *fastly.Client, s *fastly.Service, newHealthChecks []fastly.HealthCheck) error {
 	newversion, err := prepareNewVersion(client, s)
 	if err != nil {
 		return err
 	}
 
-	for i := range newHealthChecks {
-		if newHealthChecks[i].HTTPVersion == "" {
-			newHealthChecks[i].HTTPVersion = defaultHealthCheckHTTPVersion
-		}
-	}
+	prepareHealthChecks(newHealthChecks)
 
 	existingHealthChecks, _, err := client.HealthCheck.List(s.ID, newversion.Number)
 	if err != nil {
@@ -349,16 +729,21 @@ func syncSettings(client *fastly.Client, s *fastly.Service, newSettings fastly.S
 	return nil
 }
 
+// prepareDomains replaces tokens in domain names.
+func prepareDomains(s *fastly.Service, domains []fastly.Domain) {
+	r := strings.NewReplacer("_servicename_", s.Name)
+	for i := range domains {
+		domains[i].Name = r.Replace(domains[i].Name)
+	}
+}
+
 func syncDomains(client *fastly.Client, s *fastly.Service, newDomains []fastly.Domain) error {
 	newversion, err := prepareNewVersion(client, s)
 	if err != nil {
 		return err
 	}
 
-	r := strings.NewReplacer("_servicename_", s.Name)
-	for i := range newDomains {
-		newDomains[i].Name = r.Replace(newDomains[i].Name)
-	}
+	prepareDomains(s, newDomains)
 
 	existingDomains, _, err := client.Domain.List(s.ID, newversion.Number)
 	if err != nil {
@@ -407,17 +792,22 @@ func syncDomains(client *fastly.Client, s *fastly.Service, newDomains []fastly.D
 	return nil
 }
 
+// prepareSyslogs replaces tokens in syslog addresses.
+func prepareSyslogs(s *fastly.Service, syslogs []fastly.Syslog) {
+	r := strings.NewReplacer("_servicename_", s.Name, "_prefix_", siteConfigs[s.Name].IPPrefix, "_suffix_", siteConfigs[s.Name].IPSuffix)
+	for i := range syslogs {
+		syslogs[i].TLSHostname = r.Replace(syslogs[i].TLSHostname)
+		syslogs[i].Address = r.Replace(syslogs[i].Address)
+	}
+}
+
 func syncSyslogs(client *fastly.Client, s *fastly.Service, newSyslogs []fastly.Syslog) error {
 	newversion, err := prepareNewVersion(client, s)
 	if err != nil {
 		return err
 	}
 
-	r := strings.NewReplacer("_servicename_", s.Name, "_prefix_", siteConfigs[s.Name].IPPrefix, "_suffix_", siteConfigs[s.Name].IPSuffix)
-	for i := range newSyslogs {
-		newSyslogs[i].TLSHostname = r.Replace(newSyslogs[i].TLSHostname)
-		newSyslogs[i].Address = r.Replace(newSyslogs[i].Address)
-	}
+	prepareSyslogs(s, newSyslogs)
 
 	existingSyslogs, _, err := client.Syslog.List(s.ID, newversion.Number)
 	if err != nil {
@@ -466,12 +856,9 @@ func syncSyslogs(client *fastly.Client, s *fastly.Service, newSyslogs []fastly.S
 	return nil
 }
 
-func syncS3s(client *fastly.Client, s *fastly.Service, newS3s []fastly.S3) error {
-	newversion, err := prepareNewVersion(client, s)
-	if err != nil {
-		return err
-	}
-
+// prepareS3s replaces tokens in S3 configs and fills in defaults the API
+// applies to them.
+func prepareS3s(s *fastly.Service, s3s []fastly.S3) {
 	accessKey := os.Getenv("FASTLY_S3_ACCESS_KEY")
 	secretKey := os.Getenv("FASTLY_S3_SECRET_KEY")
 	if accessKey == "" {
@@ -482,13 +869,22 @@ func syncS3s(client *fastly.Client, s *fastly.Service, newS3s []fastly.S3) error
 	}
 
 	r := strings.NewReplacer("_servicename_", s.Name, "_s3accesskey_", accessKey, "_s3secretkey_", secretKey)
-	for i := range newS3s {
-		if newS3s[i].TimestampFormat == "" {
-			newS3s[i].TimestampFormat = defaultS3TimestampFormat
+	for i := range s3s {
+		if s3s[i].TimestampFormat == "" {
+			s3s[i].TimestampFormat = defaultS3TimestampFormat
 		}
-		newS3s[i].Path = r.Replace(newS3s[i].Path)
-		newS3s[i].BucketName = r.Replace(newS3s[i].BucketName)
+		s3s[i].Path = r.Replace(s3s[i].Path)
+		s3s[i].BucketName = r.Replace(s3s[i].BucketName)
 	}
+}
+
+func syncS3s(client *fastly.Client, s *fastly.Service, newS3s []fastly.S3) error {
+	newversion, err := prepareNewVersion(client, s)
+	if err != nil {
+		return err
+	}
+
+	prepareS3s(s, newS3s)
 
 	existingS3s, _, err := client.S3.List(s.ID, newversion.Number)
 	if err != nil {
@@ -804,14 +1200,15 @@ func syncConditions(client *fastly.Client, s *fastly.Service, newConditions []fa
 
 // Returns true if we made any changes, as that means we are activatable
 // despite there being no diff.
-func syncDictionaries(client *fastly.Client, s *fastly.Service, newDictionaries []fastly.Dictionary) (bool, error) {
+func syncDictionaries(client *fastly.Client, s *fastly.Service, newDictionaries []fastlyapi.Dictionary) (bool, error) {
+	api := fastlyapi.New(client)
 	var changesMade bool
 	newversion, err := prepareNewVersion(client, s)
 	if err != nil {
 		return changesMade, err
 	}
 
-	existingDictionaries, _, err := client.Dictionary.List(s.ID, newversion.Number)
+	existingDictionaries, _, err := api.Dictionary.List(s.ID, newversion.Number)
 	if err != nil {
 		return changesMade, err
 	}
@@ -822,6 +1219,12 @@ func syncDictionaries(client *fastly.Client, s *fastly.Service, newDictionaries
 		dictionary.Version = 0
 		dictionary.ID = ""
 		for i, newDictionary := range newDictionaries {
+			// WriteOnly can only be set when a dictionary is created, so
+			// a difference in it alone is not an update.
+			if dictionary.Name == newDictionary.Name && dictionary.WriteOnly != newDictionary.WriteOnly {
+				fmt.Fprintf(os.Stderr, "Warning: dictionary %s of service %s has write_only set to %t, but the config sets %t. It can only be set when the dictionary is created.\n", dictionary.Name, s.Name, dictionary.WriteOnly, newDictionary.WriteOnly)
+				newDictionary.WriteOnly = dictionary.WriteOnly
+			}
 			if *dictionary == newDictionary {
 				log.Debug(fmt.Sprintf("Found matching dictionary %s. Not creating.\n", dictionary.Name))
 				newDictionaries = append(newDictionaries[:i], newDictionaries[i+1:]...)
@@ -829,7 +1232,7 @@ func syncDictionaries(client *fastly.Client, s *fastly.Service, newDictionaries
 				break
 			} else if dictionary.Name == newDictionary.Name {
 				log.Debug(fmt.Sprintf("Found mismatched existing dictionary %s. Updating.\n", dictionary.Name))
-				if _, _, err := client.Dictionary.Update(s.ID, newversion.Number, dictionary.Name, &newDictionary); err != nil {
+				if _, _, err := api.Dictionary.Update(s.ID, newversion.Number, dictionary.Name, &newDictionary); err != nil {
 					return changesMade, err
 				}
 				changesMade = true
@@ -849,11 +1252,11 @@ func syncDictionaries(client *fastly.Client, s *fastly.Service, newDictionaries
 	}
 
 	for _, dictionary := range newDictionaries {
-		if dictionary == (fastly.Dictionary{}) {
+		if dictionary == (fastlyapi.Dictionary{}) {
 			continue
 		}
 		log.Debug(fmt.Sprintf("Creating missing dictionary %s.\n", dictionary.Name))
-		_, _, err := client.Dictionary.Create(s.ID, newversion.Number, &dictionary)
+		_, _, err := api.Dictionary.Create(s.ID, newversion.Number, &dictionary)
 		if err != nil {
 			return changesMade, err
 		}
@@ -944,24 +1347,21 @@ func checkMutuallyExclusive(a, b, c, d string) bool {
 	return true
 }
 
-func syncBackends(client *fastly.Client, s *fastly.Service, newBackends []fastly.Backend) (bool, error) {
-	var changesMade bool
-	newversion, err := prepareNewVersion(client, s)
-	if err != nil {
-		return changesMade, err
-	}
-
+// prepareBackends replaces tokens in backends and fills in the address fields
+// the API derives from one another, so they compare equal to those returned
+// by the API.
+func prepareBackends(s *fastly.Service, backends []fastly.Backend) error {
 	r := strings.NewReplacer("_servicename_", s.Name, "_prefix_", siteConfigs[s.Name].IPPrefix, "_suffix_", siteConfigs[s.Name].IPSuffix)
-	for i, b := range newBackends {
-		newBackends[i].Address = r.Replace(b.Address)
-		newBackends[i].Hostname = r.Replace(b.Hostname)
-		newBackends[i].IPV4 = r.Replace(b.IPV4)
-		newBackends[i].IPV6 = r.Replace(b.IPV6)
-		newBackends[i].SSLCertHostname = r.Replace(b.SSLCertHostname)
-	}
-	for i, b := range newBackends {
+	for i, b := range backends {
+		backends[i].Address = r.Replace(b.Address)
+		backends[i].Hostname = r.Replace(b.Hostname)
+		backends[i].IPV4 = r.Replace(b.IPV4)
+		backends[i].IPV6 = r.Replace(b.IPV6)
+		backends[i].SSLCertHostname = r.Replace(b.SSLCertHostname)
+	}
+	for i, b := range backends {
 		if !checkMutuallyExclusive(b.Address, b.Hostname, b.IPV4, b.IPV6) {
-			return changesMade, fmt.Errorf("Backend %s can only have one of Address, Hostname, IPV4, or IPV6 specified.", b.Name)
+			return fmt.Errorf("Backend %s can only have one of Address, Hostname, IPV4, or IPV6 specified.", b.Name)
 		}
 		// The Address field is automatically filled by the API with
 		// the Hostname, IPV4, or IPV6 value if one of those are
@@ -971,21 +1371,34 @@ func syncBackends(client *fastly.Client, s *fastly.Service, newBackends []fastly
 			parsed := net.ParseIP(b.Address)
 			if parsed != nil {
 				if strings.Contains(":", parsed.String()) {
-					newBackends[i].IPV6 = parsed.String()
+					backends[i].IPV6 = parsed.String()
 				} else {
-					newBackends[i].IPV4 = parsed.String()
+					backends[i].IPV4 = parsed.String()
 				}
 			} else {
-				newBackends[i].Hostname = b.Address
+				backends[i].Hostname = b.Address
 			}
 		} else if b.Hostname != "" {
-			newBackends[i].Address = b.Hostname
+			backends[i].Address = b.Hostname
 		} else if b.IPV4 != "" {
-			newBackends[i].Address = b.IPV4
+			backends[i].Address = b.IPV4
 		} else if b.IPV6 != "" {
-			newBackends[i].Address = b.IPV6
+			backends[i].Address = b.IPV6
 		}
 	}
+	return nil
+}
+
+func syncBackends(client *fastly.Client, s *fastly.Service, newBackends []fastly.Backend) (bool, error) {
+	var changesMade bool
+	newversion, err := prepareNewVersion(client, s)
+	if err != nil {
+		return changesMade, err
+	}
+
+	if err := prepareBackends(s, newBackends); err != nil {
+		return changesMade, err
+	}
 
 	existingBackends, _, err := client.Backend.List(s.ID, newversion.Number)
 	if err != nil {
@@ -1037,7 +1450,10 @@ func syncBackends(client *fastly.Client, s *fastly.Service, newBackends []fastly
 	return changesMade, nil
 }
 
-func syncService(client *fastly.Client, s *fastly.Service) error {
+// syncService syncs the config for a service onto a pending version. If the
+// resulting version is identical to the active version it is discarded,
+// unless force is set.
+func syncService(client *fastly.Client, s *fastly.Service, force bool) error {
 	activeVersion, err := util.GetActiveVersion(s)
 	if err != nil {
 		return err
@@ -1057,18 +1473,22 @@ func syncService(client *fastly.Client, s *fastly.Service) error {
 	// sync'd first, as if they're referenced in any other object the API
 	// will balk if they don't exist.
 	log.Debug("Syncing Dictionaries\n")
-	dictionaries := make([]fastly.Dictionary, len(config.Dictionaries))
+	dictionaries := make([]fastlyapi.Dictionary, len(config.Dictionaries))
 	copy(dictionaries, config.Dictionaries)
-	if changesMade, err = syncDictionaries(client, s, dictionaries); err != nil {
+	changed, err := syncDictionaries(client, s, dictionaries)
+	if err != nil {
 		return fmt.Errorf("Error syncing Dictionaries: %s", err)
 	}
+	changesMade = changesMade || changed
 
 	log.Debug("Syncing ACLs\n")
 	acls := make([]fastly.ACL, len(config.ACLs))
 	copy(acls, config.ACLs)
-	if changesMade, err = syncACLs(client, s, acls); err != nil {
+	changed, err = syncACLs(client, s, acls)
+	if err != nil {
 		return fmt.Errorf("Error syncing ACLs: %s", err)
 	}
+	changesMade = changesMade || changed
 
 	log.Debug("Syncing conditions\n")
 	conditions := make([]fastly.Condition, len(config.Conditions))
@@ -1108,9 +1528,11 @@ func syncService(client *fastly.Client, s *fastly.Service) error {
 	log.Debug("Syncing backends\n")
 	backends := make([]fastly.Backend, len(config.Backends))
 	copy(backends, config.Backends)
-	if changesMade, err = syncBackends(client, s, backends); err != nil {
+	changed, err = syncBackends(client, s, backends)
+	if err != nil {
 		return fmt.Errorf("Error syncing backends: %s", err)
 	}
+	changesMade = changesMade || changed
 
 	log.Debug("Syncing headers\n")
 	headers := make([]fastly.Header, len(config.Headers))
@@ -1159,14 +1581,17 @@ func syncService(client *fastly.Client, s *fastly.Service) error {
 		return fmt.Errorf("Error syncing VCLs: %s", err)
 	}
 
-	if version, ok := pendingVersions[s.ID]; ok {
+	if version, ok := getPendingVersion(s.ID); ok {
 		equal, err := util.VersionsEqual(client, s, activeVersion, version.Number)
 		if err != nil {
 			return err
 		}
-		if equal && !changesMade {
-			fmt.Printf("No changes for service %s\n", s.Name)
-			delete(pendingVersions, s.ID)
+		if equal && !changesMade && !force {
+			log.Info(fmt.Sprintf("No changes for service %s, skipping.\n", s.Name))
+			if versionCreated(s.ID) {
+				reportDraft(s, version.Number)
+			}
+			deletePendingVersion(s.ID)
 			return nil
 		}
 	}
@@ -1174,25 +1599,217 @@ func syncService(client *fastly.Client, s *fastly.Service) error {
 	return nil
 }
 
+// pushService syncs a single service and prompts for activation of any
+// resulting pending version. activateMu serializes the diff and activation
+// step so that output from concurrent pushes does not interleave. The
+// returned result describes the versions involved, even if an error occurred.
+func pushService(c *cli.Context, client *fastly.Client, s *fastly.Service, activateMu *sync.Mutex) (pushOutput, error) {
+	result := pushOutput{Service: s.Name}
+	result.OldVersion, _ = util.GetActiveVersion(s)
+
+	log.Info(fmt.Sprintln("Syncing ", s.Name))
+	if err := syncService(client, s, c.Bool("force")); err != nil {
+		if version, ok := getPendingVersion(s.ID); ok {
+			result.NewVersion = version.Number
+			reportDraft(s, version.Number)
+		}
+		return result, fmt.Errorf("Error syncing service config for %s: %s", s.Name, err)
+	}
+	if version, ok := getPendingVersion(s.ID); ok {
+		result.NewVersion = version.Number
+		if c.Bool("diff-only") {
+			activateMu.Lock()
+			defer activateMu.Unlock()
+			return result, showPendingDiff(c, client, s, &version)
+		}
+		if err := util.ValidateForActivation(c, client, s, version.Number); err != nil {
+			reportDraft(s, version.Number)
+			return result, err
+		}
+		if dir := c.String("backup"); dir != "" && !c.Bool("noop") {
+			file, err := backupService(client, s, dir)
+			if err != nil {
+				reportDraft(s, version.Number)
+				return result, fmt.Errorf("Error backing up service %s: %s", s.Name, err)
+			}
+			log.Info(fmt.Sprintf("Backed up active version of %s to %s\n", s.Name, file))
+		}
+		comment := c.String("comment")
+		if comment != "" && !c.Bool("noop") {
+			if err := util.SetVersionComment(client, s.ID, version.Number, comment); err != nil {
+				reportDraft(s, version.Number)
+				return result, err
+			}
+		}
+		activateMu.Lock()
+		defer activateMu.Unlock()
+		activated, err := util.PromptActivateVersion(c, client, s, &version)
+		result.Activated = activated
+		if comment != "" && !activated && !c.Bool("noop") {
+			// Restore the comment which marks the draft for reuse by
+			// the next push.
+			if err := util.SetVersionComment(client, s.ID, version.Number, versionComment); err != nil {
+				fmt.Fprintf(os.Stderr, "Unable to restore comment on draft version %d of %s: %s\n", version.Number, s.Name, err)
+			}
+		}
+		if err != nil {
+			// With --wait the version may have been activated even
+			// though it did not become active in time.
+			if activated {
+				return result, err
+			}
+			reportDraft(s, version.Number)
+			return result, fmt.Errorf("Error activating pending version %d for service %s: %s", version.Number, s.Name, err)
+		}
+		if !activated && !c.Bool("noop") {
+			reportDraft(s, version.Number)
+		}
+	}
+	return result, nil
+}
+
+// dryRunPush compares the config of each service against its active version
+// and prints what a push would change. Unlike --noop, no draft versions are
+// created.
+func dryRunPush(client *fastly.Client, toSync []*fastly.Service) error {
+	for _, s := range toSync {
+		version, err := util.GetActiveVersion(s)
+		if err != nil {
+			return cli.NewExitError(err.Error(), -1)
+		}
+		changes, err := compareService(client, s, version, siteConfigs[s.Name])
+		if err != nil {
+			return cli.NewExitError(fmt.Sprintf("Error comparing service %s: %s", s.Name, err), -1)
+		}
+		fmt.Printf("Dry run: changes a push would make to service %s:\n", s.Name)
+		printChanges(s, version, changes)
+		fmt.Println()
+	}
+	return nil
+}
+
+// pushStatus describes the outcome of a push of a single service for progress
+// reports.
+func pushStatus(r pushOutput, err error) string {
+	switch {
+	case err != nil:
+		return "failed"
+	case r.NewVersion == 0:
+		return "no changes"
+	case r.Activated:
+		return fmt.Sprintf("activated version %d", r.NewVersion)
+	default:
+		return fmt.Sprintf("created version %d", r.NewVersion)
+	}
+}
+
+// printPushResults prints the outcome of a push for each service which has a
+// new version. Structured output formats print every service instead.
+func printPushResults(c *cli.Context, results []pushOutput) error {
+	if outputFormat(c) != format.Table {
+		return printOutput(c, results)
+	}
+	// These lines are printed even with --quiet, and are meant to be easy
+	// to pick out with grep.
+	for _, r := range results {
+		if r.NewVersion == 0 {
+			continue
+		}
+		fmt.Printf("push: service=%s old_version=%d new_version=%d activated=%t\n", r.Service, r.OldVersion, r.NewVersion, r.Activated)
+	}
+	return nil
+}
+
+// showPendingDiff prints the diff between the active version of a service and
+// its pending version without activating anything.
+func showPendingDiff(c *cli.Context, client *fastly.Client, s *fastly.Service, v *fastly.Version) error {
+	activeVersion, err := util.GetActiveVersion(s)
+	if err != nil {
+		return err
+	}
+	diff, err := util.GetUnifiedDiff(client, s, activeVersion, v.Number)
+	if err != nil {
+		return err
+	}
+	if err := util.WriteDiffOutput(c, diff); err != nil {
+		return err
+	}
+
+	additions, removals := util.CountChanges(&diff)
+	if c.Bool("summary") {
+		util.PrintChangeSummary(s, additions, removals)
+	} else {
+		fmt.Printf("Service %s: %d additions and %d removals between active version %d and draft version %d.\n", s.Name, additions, removals, activeVersion, v.Number)
+		util.PrintDiff(s, diff, false)
+	}
+	reportDraft(s, v.Number)
+	return nil
+}
+
+// serviceSelected reports whether a service name matches the --only and
+// --skip glob patterns. A service must match one of the only patterns, if any
+// are given, and none of the skip patterns.
+func serviceSelected(name string, only, skip []string) (bool, error) {
+	for _, pattern := range skip {
+		matched, err := filepath.Match(pattern, name)
+		if err != nil {
+			return false, fmt.Errorf("Invalid --skip pattern %s: %s", pattern, err)
+		}
+		if matched {
+			return false, nil
+		}
+	}
+	if len(only) == 0 {
+		return true, nil
+	}
+	for _, pattern := range only {
+		matched, err := filepath.Match(pattern, name)
+		if err != nil {
+			return false, fmt.Errorf("Invalid --only pattern %s: %s", pattern, err)
+		}
+		if matched {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 func syncConfig(c *cli.Context) error {
+	// Messages, diffs and prompts would corrupt structured output, so they
+	// go to stderr instead.
+	if outputFormat(c) != format.Table {
+		os.Stdout = os.Stderr
+		defer func() { os.Stdout = stdout }()
+	}
 	fastlyKey := c.GlobalString("fastly-key")
-	configFile := c.GlobalString("config")
+	configFile, err := util.GetConfigFile(c)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
 
 	client := fastly.NewClient(nil, fastlyKey)
 
 	if err := readConfig(configFile); err != nil {
 		return cli.NewExitError(fmt.Sprintf("Error reading config file: %s", err), -1)
 	}
+	if c.Bool("validate-only") {
+		return validateConfig(c, client)
+	}
+	if err := checkUnknownKeys(c.GlobalBool("lax")); err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error reading config file: %s", err), -1)
+	}
 	pendingVersions = make(map[string]fastly.Version)
+	createdVersions = make(map[string]bool)
+	cloneBase = uint(c.Int("from-version"))
+	forceClone = c.Bool("force-clone")
 
-	services, _, err := client.Service.List()
+	services, _, err := fastlyapi.New(client).Service.List()
 	if err != nil {
 		return cli.NewExitError(fmt.Sprintf("Error listing services: %s", err), -1)
 	}
 
-	foundService := false
-
 	servicesPresent := make(map[string]bool)
+	var toSync []*fastly.Service
 
 	for _, s := range services {
 		servicesPresent[s.Name] = true
@@ -1203,22 +1820,97 @@ func syncConfig(c *cli.Context) error {
 		if !c.Bool("all") && !util.StringInSlice(s.Name, c.Args()) {
 			continue
 		}
-		foundService = true
-		fmt.Println("Syncing ", s.Name)
-		if err = syncService(client, s); err != nil {
-			return cli.NewExitError(fmt.Sprintf("Error syncing service config for %s: %s", s.Name, err), -1)
+		selected, err := serviceSelected(s.Name, c.StringSlice("only"), c.StringSlice("skip"))
+		if err != nil {
+			return cli.NewExitError(err.Error(), -1)
+		}
+		if !selected {
+			continue
+		}
+		toSync = append(toSync, &s.Service)
+	}
+	if len(toSync) == 0 {
+		return cli.NewExitError(fmt.Sprintf("No matching services could be found to be sync'd."), -1)
+	}
+	if c.IsSet("only") || c.IsSet("skip") {
+		log.Info(fmt.Sprintf("Selected %d services:\n", len(toSync)))
+		for _, s := range toSync {
+			log.Info(fmt.Sprintf("  %s\n", s.Name))
+		}
+		log.Info("\n")
+	}
+
+	if !c.Bool("ignore-drafts") {
+		for _, s := range toSync {
+			util.WarnDrafts(s, 0)
 		}
-		if version, ok := pendingVersions[s.ID]; ok {
-			if err = util.ValidateVersion(client, s, version.Number); err != nil {
-				return cli.NewExitError(err.Error(), -1)
+	}
+
+	if util.DryRun() {
+		return dryRunPush(client, toSync)
+	}
+
+	// Prompts cannot be answered for several services at once, so only
+	// push concurrently if no prompting will take place.
+	concurrency := c.Int("concurrency")
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	if !c.GlobalBool("assume-yes") && !c.Bool("noop") && !c.Bool("diff-only") {
+		concurrency = 1
+	}
+
+	errs := make([]error, len(toSync))
+	results := make([]pushOutput, len(toSync))
+	var progress *util.Progress
+	if len(toSync) > 1 {
+		// Each push prints messages, diffs and prompts of its own, so
+		// progress is reported a line at a time.
+		progress = util.NewProgress(len(toSync), false)
+	}
+	indexes := make(chan int)
+	var activateMu sync.Mutex
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if progress != nil {
+					progress.Update(toSync[i].Name, "pushing")
+				}
+				results[i], errs[i] = pushService(c, client, toSync[i], &activateMu)
+				if progress != nil {
+					progress.Done(toSync[i].Name, pushStatus(results[i], errs[i]))
+				}
 			}
-			if err = util.ActivateVersion(c, client, s, &version); err != nil {
-				return cli.NewExitError(fmt.Sprintf("Error activating pending version %s for service %s: %s", version.Number, s.Name, err), -1)
+		}()
+	}
+	for i := range toSync {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	if progress != nil {
+		progress.Finish()
+	}
+
+	if err := printPushResults(c, results); err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	var failed int
+	for i, s := range toSync {
+		if errs[i] != nil {
+			if failed == 0 {
+				fmt.Printf("\nErrors occurred while pushing services:\n")
 			}
+			failed++
+			fmt.Printf("  %s: %s\n", s.Name, errs[i])
 		}
 	}
-	if !foundService {
-		return cli.NewExitError(fmt.Sprintf("No matching services could be found to be sync'd."), -1)
+	if failed > 0 {
+		return cli.NewExitError(fmt.Sprintf("%d of %d services failed to push.", failed, len(toSync)), -1)
 	}
 
 	for name, _ := range siteConfigs {
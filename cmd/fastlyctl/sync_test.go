@@ -0,0 +1,132 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/alienth/go-fastly"
+)
+
+// readTestConfig reads contents as a TOML config file.
+func readTestConfig(t *testing.T, contents string) error {
+	dir, err := ioutil.TempDir("", "fastlyctl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "config.toml")
+	if err := ioutil.WriteFile(file, []byte(contents), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return readConfig(file)
+}
+
+func TestApplyDefaults(t *testing.T) {
+	const defaults = `
+[defaults]
+  [defaults.Settings]
+    DefaultTTL = 3600
+    DefaultHost = "default.example.com"
+  [[defaults.Backends]]
+    Name = "origin"
+    Address = "origin.example.com"
+`
+	tests := []struct {
+		name     string
+		service  string
+		settings fastly.Settings
+		backends []string
+	}{
+		{
+			"inherited",
+			``,
+			fastly.Settings{DefaultTTL: 3600, DefaultHost: "default.example.com"},
+			[]string{"origin.example.com"},
+		},
+		{
+			"nested table merged by key",
+			`
+  ["a".Settings]
+    DefaultHost = "a.example.com"
+`,
+			fastly.Settings{DefaultTTL: 3600, DefaultHost: "a.example.com"},
+			[]string{"origin.example.com"},
+		},
+		{
+			"empty values override",
+			`
+  ["a".Settings]
+    DefaultTTL = 0
+    DefaultHost = ""
+`,
+			fastly.Settings{},
+			[]string{"origin.example.com"},
+		},
+		{
+			"list replaced",
+			`
+  [["a".Backends]]
+    Name = "one"
+    Address = "one.example.com"
+  [["a".Backends]]
+    Name = "two"
+    Address = "two.example.com"
+`,
+			fastly.Settings{DefaultTTL: 3600, DefaultHost: "default.example.com"},
+			[]string{"one.example.com", "two.example.com"},
+		},
+	}
+	for _, test := range tests {
+		if err := readTestConfig(t, defaults+"\n[\"a\"]\n"+test.service); err != nil {
+			t.Errorf("%s: %s", test.name, err)
+			continue
+		}
+		config := siteConfigs["a"]
+		if config.Settings != test.settings {
+			t.Errorf("%s: got settings %+v, want %+v", test.name, config.Settings, test.settings)
+		}
+		var backends []string
+		for _, backend := range config.Backends {
+			backends = append(backends, backend.Address)
+		}
+		if !reflect.DeepEqual(backends, test.backends) {
+			t.Errorf("%s: got backends %q, want %q", test.name, backends, test.backends)
+		}
+	}
+}
+
+func TestApplyDefaultsCopies(t *testing.T) {
+	err := readTestConfig(t, `
+[_default_]
+  [[_default_.Backends]]
+    Name = "origin"
+    Address = "origin.example.com"
+
+["a"]
+["b"]
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	siteConfigs["a"].Backends[0].Address = "changed.example.com"
+	if address := siteConfigs["b"].Backends[0].Address; address != "origin.example.com" {
+		t.Errorf("changing one service's backend changed another's to %s", address)
+	}
+	if address := siteConfigs["_default_"].Backends[0].Address; address != "origin.example.com" {
+		t.Errorf("changing a service's backend changed the default to %s", address)
+	}
+}
+
+func TestApplyDefaultsBothNames(t *testing.T) {
+	err := readTestConfig(t, `
+[defaults]
+[_default_]
+["a"]
+`)
+	if err == nil {
+		t.Error("got no error with both defaults and _default_")
+	}
+}
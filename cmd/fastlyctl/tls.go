@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alienth/fastlyctl/fastlyapi"
+	"github.com/alienth/fastlyctl/format"
+	"github.com/alienth/go-fastly"
+	"github.com/urfave/cli"
+)
+
+// tlsCertificates returns a row for each Fastly managed subscription and each
+// certificate uploaded to the account.
+func tlsCertificates(client *fastly.Client) ([]tlsOutput, error) {
+	subscriptions, _, err := fastlyapi.New(client).TLS.ListSubscriptions()
+	if err != nil {
+		return nil, fmt.Errorf("Error listing TLS subscriptions: %s", err)
+	}
+	certificates, _, err := fastlyapi.New(client).TLS.ListCertificates()
+	if err != nil {
+		return nil, fmt.Errorf("Error listing TLS certificates: %s", err)
+	}
+
+	var output []tlsOutput
+	managed := make(map[string]bool)
+	for _, s := range subscriptions {
+		o := tlsOutput{Domains: s.Domains, State: s.State, Issuer: s.CertificateAuthority, Managed: true}
+		// A subscription may briefly have both its old and renewed
+		// certificates, in which case the latest expiry is shown.
+		for _, cert := range s.Certificates {
+			managed[cert.ID] = true
+			if cert.NotAfter > o.Expires {
+				o.Expires = cert.NotAfter
+			}
+		}
+		output = append(output, o)
+	}
+	for _, cert := range certificates {
+		if managed[cert.ID] {
+			continue
+		}
+		output = append(output, tlsOutput{Domains: cert.Domains, State: "uploaded", Issuer: cert.Issuer, Expires: cert.NotAfter})
+	}
+	return output, nil
+}
+
+func tlsList(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+
+	certificates, err := tlsCertificates(client)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	output := certificates
+	if c.IsSet("expiring") {
+		cutoff := time.Now().Add(time.Duration(c.Int("expiring")) * 24 * time.Hour)
+		output = nil
+		for _, o := range certificates {
+			// A certificate whose expiry is unknown is listed, as it
+			// may well be about to expire.
+			expires, err := time.Parse(time.RFC3339, o.Expires)
+			if err != nil || expires.Before(cutoff) {
+				output = append(output, o)
+			}
+		}
+	}
+
+	if outputFormat(c) != format.Table {
+		if output == nil {
+			output = []tlsOutput{}
+		}
+		if err := printOutput(c, output); err != nil {
+			return err
+		}
+	} else {
+		fmt.Printf("%-50s %-12s %-30s %s\n", "Domains", "State", "Issuer", "Expires")
+		for _, o := range output {
+			fmt.Printf("%-50s %-12s %-30s %s\n", strings.Join(o.Domains, ","), o.State, dashIfEmpty(o.Issuer), dashIfEmpty(o.Expires))
+		}
+	}
+
+	if c.IsSet("expiring") && len(output) > 0 {
+		return cli.NewExitError(fmt.Sprintf("%d certificates expire within %d days.", len(output), c.Int("expiring")), -1)
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/alienth/fastlyctl/log"
+	"github.com/alienth/fastlyctl/util"
+	"github.com/alienth/go-fastly"
+	"github.com/urfave/cli"
+)
+
+// validateConfig checks the loaded siteConfigs for the services selected on
+// the command line, reporting every problem found. No changes are made in
+// Fastly.
+func validateConfig(c *cli.Context, client *fastly.Client) error {
+	var problems []string
+	for _, key := range unknownConfigKeys {
+		problems = append(problems, key)
+	}
+
+	var names []string
+	for name := range siteConfigs {
+		if name == "_default_" {
+			continue
+		}
+		if !c.Bool("all") && !util.StringInSlice(name, c.Args()) {
+			continue
+		}
+		selected, err := serviceSelected(name, c.StringSlice("only"), c.StringSlice("skip"))
+		if err != nil {
+			return cli.NewExitError(err.Error(), -1)
+		}
+		if !selected {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range c.Args() {
+		if _, ok := siteConfigs[name]; !ok {
+			problems = append(problems, fmt.Sprintf("Service %s is not defined in configuration", name))
+		}
+	}
+
+	for _, name := range names {
+		if _, err := util.GetServiceByName(client, name); err != nil {
+			problems = append(problems, fmt.Sprintf("Service %s: %s", name, err))
+		}
+		for _, problem := range validateSiteConfig(siteConfigs[name]) {
+			problems = append(problems, fmt.Sprintf("Service %s: %s", name, problem))
+		}
+	}
+
+	if len(problems) > 0 {
+		fmt.Printf("Problems found in configuration:\n")
+		for _, problem := range problems {
+			fmt.Printf("  %s\n", problem)
+		}
+		return cli.NewExitError(fmt.Sprintf("%d problems found.", len(problems)), -1)
+	}
+	log.Info(fmt.Sprintf("Configuration for %d services is valid.\n", len(names)))
+	return nil
+}
+
+// validateSiteConfig checks that each object in a SiteConfig has the fields
+// required to create it.
+func validateSiteConfig(config SiteConfig) []string {
+	var problems []string
+	missing := func(kind, name, field string) {
+		if name == "" {
+			problems = append(problems, fmt.Sprintf("%s is missing %s", kind, field))
+		} else {
+			problems = append(problems, fmt.Sprintf("%s %s is missing %s", kind, name, field))
+		}
+	}
+
+	for _, d := range config.Domains {
+		if d.Name == "" {
+			missing("Domain", "", "Name")
+		}
+	}
+	for _, b := range config.Backends {
+		if b.Name == "" {
+			missing("Backend", "", "Name")
+		}
+		if b.Address == "" && b.Hostname == "" && b.IPV4 == "" && b.IPV6 == "" {
+			missing("Backend", b.Name, "one of Address, Hostname, IPV4, or IPV6")
+		} else if !checkMutuallyExclusive(b.Address, b.Hostname, b.IPV4, b.IPV6) {
+			problems = append(problems, fmt.Sprintf("Backend %s can only have one of Address, Hostname, IPV4, or IPV6 specified", b.Name))
+		}
+	}
+	for _, cond := range config.Conditions {
+		if cond.Name == "" {
+			missing("Condition", "", "Name")
+		}
+		if cond.Statement == "" {
+			missing("Condition", cond.Name, "Statement")
+		}
+		if cond.Type == 0 {
+			missing("Condition", cond.Name, "Type")
+		}
+	}
+	for _, cs := range config.CacheSettings {
+		if cs.Name == "" {
+			missing("CacheSetting", "", "Name")
+		}
+	}
+	for _, h := range config.Headers {
+		if h.Name == "" {
+			missing("Header", "", "Name")
+		}
+		if h.Action == 0 {
+			missing("Header", h.Name, "Action")
+		}
+		if h.Type == 0 {
+			missing("Header", h.Name, "Type")
+		}
+	}
+	for _, s3 := range config.S3s {
+		if s3.Name == "" {
+			missing("S3", "", "Name")
+		}
+		if s3.BucketName == "" {
+			missing("S3", s3.Name, "BucketName")
+		}
+	}
+	for _, syslog := range config.Syslogs {
+		if syslog.Name == "" {
+			missing("Syslog", "", "Name")
+		}
+		if syslog.Address == "" {
+			missing("Syslog", syslog.Name, "Address")
+		}
+	}
+	for _, g := range config.Gzips {
+		if g.Name == "" {
+			missing("Gzip", "", "Name")
+		}
+	}
+	for _, hc := range config.HealthChecks {
+		if hc.Name == "" {
+			missing("HealthCheck", "", "Name")
+		}
+	}
+	for _, d := range config.Dictionaries {
+		if d.Name == "" {
+			missing("Dictionary", "", "Name")
+		}
+	}
+	for _, acl := range config.ACLs {
+		if acl.Name == "" {
+			missing("ACL", "", "Name")
+		}
+	}
+	for _, vcl := range config.VCLs {
+		if vcl.Name == "" {
+			missing("VCL", "", "Name")
+		}
+		if vcl.File != "" && vcl.Content != "" {
+			problems = append(problems, fmt.Sprintf("VCL %s cannot specify both a File and Content", vcl.Name))
+		} else if vcl.File == "" && vcl.Content == "" {
+			missing("VCL", vcl.Name, "Content or File")
+		} else if vcl.File != "" {
+			if _, err := os.Stat(vcl.File); err != nil {
+				problems = append(problems, fmt.Sprintf("VCL %s: %s", vcl.Name, err))
+			}
+		}
+	}
+	for _, rs := range config.RequestSettings {
+		if rs.Name == "" {
+			missing("RequestSetting", "", "Name")
+		}
+	}
+	for _, ro := range config.ResponseObject {
+		if ro.Name == "" {
+			missing("ResponseObject", "", "Name")
+		}
+	}
+
+	return problems
+}
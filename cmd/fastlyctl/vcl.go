@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strconv"
+
+	"github.com/alienth/fastlyctl/fastlyapi"
+	"github.com/alienth/fastlyctl/format"
+	"github.com/alienth/fastlyctl/log"
+	"github.com/alienth/fastlyctl/util"
+	"github.com/alienth/go-fastly"
+	"github.com/urfave/cli"
+)
+
+func vclList(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+
+	serviceParam := c.Args().Get(0)
+	service, err := util.GetService(client, serviceParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	activeVersion, err := util.GetActiveVersion(service)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	vcls, _, err := client.VCL.List(service.ID, activeVersion)
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Unable to list VCLs for service %s: %s", service.Name, err), -1)
+	}
+	if outputFormat(c) != format.Table {
+		output := make([]vclOutput, 0, len(vcls))
+		for _, v := range vcls {
+			output = append(output, newVCLOutput(v))
+		}
+		return printOutput(c, output)
+	}
+
+	fmt.Printf("VCLs for %s:\n\n", service.Name)
+	for _, v := range vcls {
+		main := ""
+		if v.Main {
+			main = " (main)"
+		}
+		fmt.Printf("%s%s\n", v.Name, main)
+	}
+	return nil
+}
+
+func vclGet(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+
+	serviceParam := c.Args().Get(0)
+	nameParam := c.Args().Get(1)
+
+	service, err := util.GetService(client, serviceParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	activeVersion, err := util.GetActiveVersion(service)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	vcl, _, err := client.VCL.Get(service.ID, activeVersion, nameParam)
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error fetching VCL %s: %s", nameParam, err), -1)
+	}
+	fmt.Print(vcl.Content)
+
+	return nil
+}
+
+func vclUpload(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+
+	serviceParam := c.Args().Get(0)
+	nameParam := c.Args().Get(1)
+	fileParam := c.Args().Get(2)
+
+	content, err := ioutil.ReadFile(fileParam)
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error reading %s: %s", fileParam, err), -1)
+	}
+
+	service, err := util.GetService(client, serviceParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	version, err := draftVersion(c, client, service)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	vcl := new(fastly.VCL)
+	vcl.Name = nameParam
+	vcl.Content = string(content)
+	vcl.Main = c.Bool("main")
+
+	if _, _, err = client.VCL.Get(service.ID, version, nameParam); err == nil {
+		if _, _, err = client.VCL.Update(service.ID, version, nameParam, vcl); err != nil {
+			return cli.NewExitError(fmt.Sprintf("Error updating VCL: %s", err), -1)
+		}
+		log.Info(fmt.Sprintf("Updated VCL %s\n", nameParam))
+	} else {
+		if _, _, err = client.VCL.Create(service.ID, version, vcl); err != nil {
+			return cli.NewExitError(fmt.Sprintf("Error creating VCL: %s", err), -1)
+		}
+		log.Info(fmt.Sprintf("Created VCL %s\n", nameParam))
+	}
+
+	if err = finishDraft(c, client, service, version); err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	return nil
+}
+
+func vclGenerated(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+
+	serviceParam := c.Args().Get(0)
+	service, err := util.GetService(client, serviceParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	var version uint
+	if c.NArg() > 1 {
+		v, err := strconv.Atoi(c.Args().Get(1))
+		if err != nil {
+			return cli.NewExitError("Invalid version number.\n", -1)
+		}
+		version = uint(v)
+	} else if version, err = util.GetActiveVersion(service); err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	generated, _, err := fastlyapi.New(client).VCL.Generated(service.ID, version)
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error fetching generated VCL: %s", err), -1)
+	}
+
+	usePager := util.IsInteractive() && !c.Bool("no-pager")
+	if !c.IsSet("diff") {
+		util.Page(generated.Content, usePager)
+		return nil
+	}
+
+	other, _, err := fastlyapi.New(client).VCL.Generated(service.ID, uint(c.Int("diff")))
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error fetching generated VCL: %s", err), -1)
+	}
+	diff, err := util.DiffStrings(other.Content, generated.Content)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	util.PrintDiff(service, diff, usePager)
+
+	return nil
+}
@@ -3,7 +3,11 @@ package main
 import (
 	"fmt"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/alienth/fastlyctl/format"
+	"github.com/alienth/fastlyctl/log"
 	"github.com/alienth/fastlyctl/util"
 	"github.com/alienth/go-fastly"
 	"github.com/urfave/cli"
@@ -12,44 +16,469 @@ import (
 func versionList(c *cli.Context) error {
 	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
 	serviceParam := c.Args().Get(0)
-	service, err := util.GetServiceByName(client, serviceParam)
+	service, err := util.GetService(client, serviceParam)
 	if err != nil {
 		return cli.NewExitError(err.Error(), -1)
 	}
+	versions, err := filterVersions(c, service.Versions)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	if outputFormat(c) != format.Table {
+		output := make([]versionOutput, 0, len(versions))
+		for _, version := range versions {
+			output = append(output, newVersionOutput(version))
+		}
+		return printOutput(c, output)
+	}
+
 	fmt.Printf("Versions for %s:\n\n", service.Name)
-	fmt.Printf("%5s %-27s %-27s %s\n", "ID", "Created", "Updated", "Comment")
+	fmt.Printf("%7s  %-6s  %-6s  %-6s  %-27s  %s\n", "Version", "Active", "Locked", "Staged", "Updated", "Comment")
+	for _, version := range versions {
+		fmt.Printf("%7d  %-6s  %-6s  %-6s  %-27s  %s\n", version.Number, util.YesNo(version.Active), util.YesNo(version.Locked), util.YesNo(version.Staging), version.Updated, version.Comment)
+	}
+
+	return nil
+}
+
+// filterVersions applies the --since, --until, and --limit flags of version
+// list. Versions are filtered by the time they were last updated.
+func filterVersions(c *cli.Context, versions []*fastly.Version) ([]*fastly.Version, error) {
+	var since, until time.Time
+	var err error
+	if c.IsSet("since") {
+		if since, err = parseTime(c.String("since")); err != nil {
+			return nil, fmt.Errorf("Invalid --since: %s", err)
+		}
+	}
+	if c.IsSet("until") {
+		if until, err = parseTime(c.String("until")); err != nil {
+			return nil, fmt.Errorf("Invalid --until: %s", err)
+		}
+	}
+
+	var filtered []*fastly.Version
+	for _, version := range versions {
+		if !since.IsZero() || !until.IsZero() {
+			timestamp := version.Updated
+			if timestamp == "" {
+				timestamp = version.Created
+			}
+			updated, err := time.Parse(time.RFC3339, timestamp)
+			if err != nil {
+				return nil, fmt.Errorf("Unable to filter versions: version %d has an invalid update time %q.", version.Number, timestamp)
+			}
+			if (!since.IsZero() && updated.Before(since)) || (!until.IsZero() && updated.After(until)) {
+				continue
+			}
+		}
+		filtered = append(filtered, version)
+	}
+
+	// Versions are listed oldest first, so the most recent are at the end.
+	if limit := c.Int("limit"); limit > 0 && len(filtered) > limit {
+		filtered = filtered[len(filtered)-limit:]
+	}
+	return filtered, nil
+}
+
+// parseTime parses either an RFC3339 timestamp, or a duration before now such
+// as 12h or 7d.
+func parseTime(s string) (time.Time, error) {
+	if strings.HasSuffix(s, "d") {
+		if days, err := strconv.Atoi(strings.TrimSuffix(s, "d")); err == nil {
+			return time.Now().AddDate(0, 0, -days), nil
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither an RFC3339 time nor a duration such as 7d", s)
+	}
+	return t, nil
+}
+
+// latestDraft returns the highest numbered version of a service which has not
+// been locked by activation.
+func latestDraft(service *fastly.Service) (*fastly.Version, error) {
+	var draft *fastly.Version
 	for _, version := range service.Versions {
-		active := ""
-		if version.Active {
-			active = "*"
+		if !version.Locked && (draft == nil || version.Number > draft.Number) {
+			draft = version
 		}
-		fmt.Printf("%2s %4d %-27s %-27s %s\n", active, version.Number, version.Created, version.Updated, version.Comment)
 	}
+	if draft == nil {
+		return nil, fmt.Errorf("Service %s has no draft versions.", service.Name)
+	}
+	return draft, nil
+}
 
-	return nil
+// versionArg parses a VERSION argument, which is either a version number or
+// latest for the latest draft version.
+func versionArg(service *fastly.Service, arg string) (uint, error) {
+	if arg == "latest" {
+		draft, err := latestDraft(service)
+		if err != nil {
+			return 0, err
+		}
+		return draft.Number, nil
+	}
+	number, err := strconv.Atoi(arg)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid version number.\n")
+	}
+	return uint(number), nil
 }
 
 func versionValidate(c *cli.Context) error {
 	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
 	serviceParam := c.Args().Get(0)
+
+	service, err := util.GetService(client, serviceParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	version, err := versionArg(service, c.Args().Get(1))
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	warnings, err := util.ValidateVersion(client, service, version)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	if len(warnings) > 0 {
+		util.PrintValidationWarnings(service, version, warnings)
+		if c.Bool("strict") {
+			return cli.NewExitError(fmt.Sprintf("Version %d has %d validation warnings.", version, len(warnings)), -1)
+		}
+	}
+
+	return nil
+}
+
+func versionActivate(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+	serviceParam := c.Args().Get(0)
+
+	service, err := util.GetService(client, serviceParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	version, err := versionArg(service, c.Args().Get(1))
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	latest := c.Args().Get(1) == "latest"
+
+	activeVersion, err := util.GetActiveVersion(service)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	// Re-running an activation, such as on a retry in automation, does
+	// nothing.
+	if version == activeVersion {
+		log.Info(fmt.Sprintf("Version %d is already active on service %s. Nothing to do.\n", version, service.Name))
+		return nil
+	}
+	// Warnings are printed, and with --fail-on-warnings stop the
+	// activation.
+	if err := util.ValidateForActivation(c, client, service, version); err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	if latest && version < activeVersion && !c.Bool("force") {
+		return cli.NewExitError(fmt.Sprintf("The latest draft of service %s, version %d, is older than the active version %d. Use --force to activate it anyway.", service.Name, version, activeVersion), -1)
+	}
+
+	if !c.Bool("force") {
+		equal, err := util.VersionsEqual(client, service, activeVersion, version)
+		if err != nil {
+			return cli.NewExitError(err.Error(), -1)
+		}
+		// Unlike push, it is not known what was changed on the version, so
+		// the dictionaries and ACLs which the diff omits are compared too.
+		if equal {
+			if equal, err = util.ContainersEqual(client, service, activeVersion, version); err != nil {
+				return cli.NewExitError(err.Error(), -1)
+			}
+		}
+		if equal {
+			log.Info(fmt.Sprintf("No changes for %s, skipping. Use --force to activate anyway.\n", service.Name))
+			return nil
+		}
+	}
+
+	// The latest draft may have been made by anyone, so it is reviewed
+	// and confirmed as it would be in a push.
+	if latest {
+		if _, err := util.PromptActivateVersion(c, client, service, &fastly.Version{Number: version}); err != nil {
+			return cli.NewExitError(err.Error(), -1)
+		}
+		return nil
+	}
+
+	if c.String("diff-output") != "" || c.Bool("summary") {
+		diff, err := util.GetUnifiedDiff(client, service, activeVersion, version)
+		if err != nil {
+			return cli.NewExitError(fmt.Sprintf("Error fetching diff: %s", err), -1)
+		}
+		if err = util.WriteDiffOutput(c, diff); err != nil {
+			return cli.NewExitError(err.Error(), -1)
+		}
+		if c.Bool("summary") {
+			additions, removals := util.CountChanges(&diff)
+			util.PrintChangeSummary(service, additions, removals)
+		}
+	}
+
+	err = util.WithRetry(func() error {
+		_, _, err := client.Version.Activate(service.ID, version)
+		return err
+	})
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error activating version: %s", err), -1)
+	} else if util.DryRun() {
+		fmt.Printf("Dry run: would activate version %d on service %s.\n", version, serviceParam)
+	} else {
+		util.ForgetService(service)
+		log.Info(fmt.Sprintf("Version %d on service %s successfully activated!\n", version, serviceParam))
+	}
+	if c.Bool("wait") {
+		if err = util.WaitForActive(client, service, version, c.Duration("wait-timeout")); err != nil {
+			return cli.NewExitError(err.Error(), -1)
+		}
+	}
+
+	return nil
+}
+
+func versionDiff(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+	serviceParam := c.Args().Get(0)
+
+	service, err := util.GetService(client, serviceParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	var from, to int
+	if c.NArg() > 2 {
+		if from, err = strconv.Atoi(c.Args().Get(1)); err != nil {
+			return cli.NewExitError("Invalid version number.\n", -1)
+		}
+		if to, err = strconv.Atoi(c.Args().Get(2)); err != nil {
+			return cli.NewExitError("Invalid version number.\n", -1)
+		}
+	} else {
+		activeVersion, err := util.GetActiveVersion(service)
+		if err != nil {
+			return cli.NewExitError(err.Error(), -1)
+		}
+		from = int(activeVersion)
+		if to, err = strconv.Atoi(c.Args().Get(1)); err != nil {
+			return cli.NewExitError("Invalid version number.\n", -1)
+		}
+	}
+
+	// Formats other than text are emitted as Fastly renders them, for
+	// attaching to tickets or feeding to other tools.
+	if diffFormat := c.String("format"); diffFormat != fastly.DiffFormatText {
+		diff, err := util.GetFormattedDiff(client, service, uint(from), uint(to), diffFormat)
+		if err != nil {
+			return cli.NewExitError(fmt.Sprintf("Error fetching diff: %s", err), -1)
+		}
+		fmt.Println(strings.TrimSuffix(diff, "\n"))
+		return nil
+	}
+
+	diff, err := util.GetUnifiedDiff(client, service, uint(from), uint(to))
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error fetching diff: %s", err), -1)
+	}
+
+	util.PrintDiff(service, diff, util.IsInteractive() && !c.Bool("no-pager"))
+
+	return nil
+}
+
+// lastDeployed returns the most recent version below the given version which
+// Fastly reports as deployed, or nil if there is none. A below of 0 means any
+// version. Locked versions are not considered, as versions can be locked
+// without ever being activated.
+func lastDeployed(service *fastly.Service, below uint) *fastly.Version {
+	var target *fastly.Version
+	for _, version := range service.Versions {
+		if (below != 0 && version.Number >= below) || !version.Deployed {
+			continue
+		}
+		if target == nil || version.Number > target.Number {
+			target = version
+		}
+	}
+	return target
+}
+
+// rollbackTarget returns the version to roll back to: arg if given, otherwise
+// the most recent version below the active version which was deployed.
+func rollbackTarget(service *fastly.Service, arg string) (*fastly.Version, error) {
+	activeVersion, err := util.GetActiveVersion(service)
+	if err != nil {
+		return nil, err
+	}
+
+	if arg != "" {
+		number, err := strconv.Atoi(arg)
+		if err != nil || number <= 0 {
+			return nil, fmt.Errorf("Invalid version number %s.", arg)
+		}
+		if uint(number) == activeVersion {
+			return nil, fmt.Errorf("Version %d is already active on service %s.", number, service.Name)
+		}
+		for _, version := range service.Versions {
+			if version.Number == uint(number) {
+				return version, nil
+			}
+		}
+		return nil, fmt.Errorf("Version %d not found on service %s", number, service.Name)
+	}
+
+	target := lastDeployed(service, activeVersion)
+	if target == nil {
+		return nil, fmt.Errorf("Unable to tell which version was active before version %d on service %s. Specify the version to roll back to.", activeVersion, service.Name)
+	}
+	return target, nil
+}
+
+func versionRollback(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+	serviceParam := c.Args().Get(0)
+
+	service, err := util.GetService(client, serviceParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	target, err := rollbackTarget(service, c.Args().Get(1))
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	log.Info(fmt.Sprintf("Rolling back service %s to version %d\n", service.Name, target.Number))
+
+	if err = util.ActivateVersion(c, client, service, target); err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error activating version: %s", err), -1)
+	}
+
+	return nil
+}
+
+func versionClone(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+	serviceParam := c.Args().Get(0)
+
+	service, err := util.GetService(client, serviceParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	var version uint
+	if c.NArg() > 1 {
+		v, err := strconv.Atoi(c.Args().Get(1))
+		if err != nil {
+			return cli.NewExitError("Invalid version number.\n", -1)
+		}
+		version = uint(v)
+	} else if version, err = util.GetActiveVersion(service); err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	if util.DryRun() {
+		fmt.Printf("Dry run: would clone version %d on service %s to a new version.\n", version, service.Name)
+		return nil
+	}
+	newVersion, _, err := client.Version.Clone(service.ID, version)
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error cloning version: %s", err), -1)
+	}
+	fmt.Printf("Cloned version %d on service %s to new version %d\n", version, service.Name, newVersion.Number)
+
+	return nil
+}
+
+func versionDeactivate(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+	serviceParam := c.Args().Get(0)
+
+	service, err := util.GetService(client, serviceParam)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	activeVersion, err := util.GetActiveVersion(service)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	if !c.GlobalBool("assume-yes") {
+		question := fmt.Sprintf("Deactivate version %d for service %s? This will stop the service from serving traffic.", activeVersion, service.Name)
+		proceed, err := util.Prompt(question)
+		if err != nil {
+			return cli.NewExitError(err.Error(), -1)
+		}
+		if !proceed {
+			return nil
+		}
+	}
+
+	if util.DryRun() {
+		fmt.Printf("Dry run: would deactivate version %d on service %s.\n", activeVersion, service.Name)
+		return nil
+	}
+	if _, _, err = client.Version.Deactivate(service.ID, activeVersion); err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error deactivating version: %s", err), -1)
+	}
+	util.ForgetService(service)
+	log.Info(fmt.Sprintf("Version %d on service %s successfully deactivated.\n", activeVersion, service.Name))
+
+	return nil
+}
+
+func versionLock(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+	serviceParam := c.Args().Get(0)
 	version, err := strconv.Atoi(c.Args().Get(1))
 	if err != nil {
 		return cli.NewExitError("Invalid version number.\n", -1)
 	}
 
 	var service *fastly.Service
-	if service, err = util.GetServiceByName(client, serviceParam); err != nil {
+	if service, err = util.GetService(client, serviceParam); err != nil {
 		return cli.NewExitError(err.Error(), -1)
 	}
 
-	if err := util.ValidateVersion(client, service, uint(version)); err != nil {
-		return cli.NewExitError(err.Error(), -1)
+	existing, _, err := client.Version.Get(service.ID, uint(version))
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Unable to find version %d on service %s: %s", version, service.Name, err), -1)
 	}
 
+	if util.DryRun() {
+		fmt.Printf("Dry run: would lock version %d on service %s.\n", version, service.Name)
+		return nil
+	}
+	if _, _, err = client.Version.Lock(service.ID, uint(version)); err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error locking version: %s", err), -1)
+	}
+	state := "inactive"
+	if existing.Active {
+		state = "active"
+	}
+	log.Info(fmt.Sprintf("Version %d (%s) on service %s successfully locked.\n", version, state, service.Name))
+
 	return nil
 }
 
-func versionActivate(c *cli.Context) error {
+func versionAnnotate(c *cli.Context) error {
 	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
 	serviceParam := c.Args().Get(0)
 	version, err := strconv.Atoi(c.Args().Get(1))
@@ -58,15 +487,68 @@ func versionActivate(c *cli.Context) error {
 	}
 
 	var service *fastly.Service
-	if service, err = util.GetServiceByName(client, serviceParam); err != nil {
+	if service, err = util.GetService(client, serviceParam); err != nil {
 		return cli.NewExitError(err.Error(), -1)
 	}
 
-	if _, _, err = client.Version.Activate(service.ID, uint(version)); err != nil {
-		return cli.NewExitError(fmt.Sprintf("Error activating version: %s", err), -1)
-	} else {
-		fmt.Printf("Version %d on service %s successfully activated!\n", version, serviceParam)
+	if c.NArg() < 3 {
+		existing, _, err := client.Version.Get(service.ID, uint(version))
+		if err != nil {
+			return cli.NewExitError(fmt.Sprintf("Unable to find version %d on service %s: %s", version, service.Name, err), -1)
+		}
+		fmt.Println(existing.Comment)
+		return nil
 	}
 
+	comment := strings.Join(c.Args()[2:], " ")
+	if err = util.SetVersionComment(client, service.ID, uint(version), comment); err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	log.Info(fmt.Sprintf("Set comment on version %d of service %s.\n", version, service.Name))
+
 	return nil
 }
+
+// draftVersion returns the version number which versioned changes should be
+// made against. If --version was given that version is used, otherwise the
+// active version is cloned into a new draft.
+func draftVersion(c *cli.Context, client *fastly.Client, service *fastly.Service) (uint, error) {
+	if c.IsSet("version") {
+		return uint(c.Int("version")), nil
+	}
+
+	activeVersion, err := util.GetActiveVersion(service)
+	if err != nil {
+		return 0, err
+	}
+	if util.DryRun() {
+		// No draft is created, so reads made while preparing the
+		// changes are made against the active version instead.
+		fmt.Printf("Dry run: would clone active version %d to a new draft version\n", activeVersion)
+		return activeVersion, nil
+	}
+	newVersion, _, err := client.Version.Clone(service.ID, activeVersion)
+	if err != nil {
+		return 0, fmt.Errorf("Error cloning version %d: %s", activeVersion, err)
+	}
+	fmt.Printf("Cloned active version %d to new draft version %d\n", activeVersion, newVersion.Number)
+	return newVersion.Number, nil
+}
+
+// finishDraft is called once versioned changes have been made to a draft
+// version. If --activate was given the version is validated and activated,
+// otherwise the user is reminded to do so.
+func finishDraft(c *cli.Context, client *fastly.Client, service *fastly.Service, version uint) error {
+	if util.DryRun() {
+		fmt.Printf("Dry run: no changes were made to service %s.\n", service.Name)
+		return nil
+	}
+	if !c.Bool("activate") {
+		fmt.Printf("Changes made on draft version %d of service %s. Validate and activate version %d to apply.\n", version, service.Name, version)
+		return nil
+	}
+	if err := util.ValidateForActivation(c, client, service, version); err != nil {
+		return err
+	}
+	return util.ActivateVersion(c, client, service, &fastly.Version{Number: version})
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"flag"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/alienth/go-fastly"
+	"github.com/urfave/cli"
+)
+
+// versionListContext returns a context for version list with the given flags.
+func versionListContext(t *testing.T, args ...string) *cli.Context {
+	set := flag.NewFlagSet("list", flag.ContinueOnError)
+	set.String("since", "", "")
+	set.String("until", "", "")
+	set.Int("limit", 0, "")
+	if err := set.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	return cli.NewContext(nil, set, nil)
+}
+
+func TestFilterVersions(t *testing.T) {
+	versions := []*fastly.Version{
+		{Number: 1, Updated: "2026-01-01T00:00:00Z"},
+		{Number: 2, Updated: "2026-02-01T00:00:00Z"},
+		{Number: 3, Created: "2026-03-01T00:00:00Z"},
+		{Number: 4, Updated: "2026-04-01T00:00:00Z"},
+	}
+	tests := []struct {
+		name string
+		args []string
+		want []uint
+	}{
+		{"no flags", nil, []uint{1, 2, 3, 4}},
+		{"since", []string{"--since", "2026-02-01T00:00:00Z"}, []uint{2, 3, 4}},
+		{"until", []string{"--until", "2026-02-15T00:00:00Z"}, []uint{1, 2}},
+		{"created when never updated", []string{"--since", "2026-02-15T00:00:00Z", "--until", "2026-03-15T00:00:00Z"}, []uint{3}},
+		{"limit keeps the newest", []string{"--limit", "2"}, []uint{3, 4}},
+		{"limit above count", []string{"--limit", "10"}, []uint{1, 2, 3, 4}},
+		{"since and limit", []string{"--since", "2026-01-15T00:00:00Z", "--limit", "1"}, []uint{4}},
+		{"none match", []string{"--since", "2027-01-01T00:00:00Z"}, nil},
+	}
+	for _, test := range tests {
+		filtered, err := filterVersions(versionListContext(t, test.args...), versions)
+		if err != nil {
+			t.Errorf("%s: %s", test.name, err)
+			continue
+		}
+		var got []uint
+		for _, version := range filtered {
+			got = append(got, version.Number)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got versions %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestFilterVersionsErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		versions []*fastly.Version
+	}{
+		{"invalid since", []string{"--since", "yesterday"}, nil},
+		{"invalid until", []string{"--until", "tomorrow"}, nil},
+		{"invalid update time", []string{"--since", "7d"}, []*fastly.Version{{Number: 1, Updated: "2026-01-01"}}},
+	}
+	for _, test := range tests {
+		if _, err := filterVersions(versionListContext(t, test.args...), test.versions); err == nil {
+			t.Errorf("%s: got no error", test.name)
+		}
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"2026-01-02T03:04:05Z", time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{"12h", now.Add(-12 * time.Hour)},
+		{"90m", now.Add(-90 * time.Minute)},
+		{"7d", now.AddDate(0, 0, -7)},
+		{"0d", now},
+	}
+	for _, test := range tests {
+		got, err := parseTime(test.in)
+		if err != nil {
+			t.Errorf("%s: %s", test.in, err)
+			continue
+		}
+		// Relative times are taken from the clock when parsed.
+		if diff := got.Sub(test.want); diff < -time.Minute || diff > time.Minute {
+			t.Errorf("%s: got %s, want %s", test.in, got, test.want)
+		}
+	}
+
+	for _, in := range []string{"", "d", "7days", "2026-01-02", "soon"} {
+		if _, err := parseTime(in); err == nil {
+			t.Errorf("%q: got no error", in)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/alienth/fastlyctl/fastlyapi"
+	"github.com/alienth/fastlyctl/format"
+	"github.com/alienth/fastlyctl/util"
+	"github.com/alienth/go-fastly"
+	"github.com/urfave/cli"
+)
+
+func wafList(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+
+	service, err := util.GetService(client, c.Args().Get(0))
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	activeVersion, err := util.GetActiveVersion(service)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	wafs, _, err := fastlyapi.New(client).WAF.List(service.ID, activeVersion)
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error listing WAFs for service %s: %s", service.Name, err), -1)
+	}
+	if outputFormat(c) != format.Table {
+		output := make([]wafOutput, 0, len(wafs))
+		for _, w := range wafs {
+			output = append(output, newWAFOutput(w))
+		}
+		return printOutput(c, output)
+	}
+
+	fmt.Printf("WAFs for %s:\n\n", service.Name)
+	fmt.Printf("%-25s %-8s %8s %8s  %s\n", "ID", "Status", "Logging", "Blocking", "Last push")
+	for _, w := range wafs {
+		status := "enabled"
+		if w.Disabled {
+			status = "disabled"
+		}
+		fmt.Printf("%-25s %-8s %8d %8d  %s\n", w.ID, status, w.LoggingCount, w.BlockingCount, dashIfEmpty(w.LastPush))
+	}
+	return nil
+}
+
+func wafRules(c *cli.Context) error {
+	client := fastly.NewClient(nil, c.GlobalString("fastly-key"))
+
+	service, err := util.GetService(client, c.Args().Get(0))
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	wafID := c.Args().Get(1)
+
+	statuses, _, err := fastlyapi.New(client).WAF.ListRuleStatuses(service.ID, wafID)
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error listing rules for WAF %s: %s", wafID, err), -1)
+	}
+	var rules []*fastlyapi.WAFRuleStatus
+	for _, r := range statuses {
+		if r.Status != "disabled" || c.Bool("all") {
+			rules = append(rules, r)
+		}
+	}
+
+	if outputFormat(c) != format.Table {
+		output := make([]wafRuleOutput, 0, len(rules))
+		for _, r := range rules {
+			output = append(output, newWAFRuleOutput(r))
+		}
+		return printOutput(c, output)
+	}
+
+	fmt.Printf("%10s  %s\n", "Rule ID", "Status")
+	for _, r := range rules {
+		fmt.Printf("%10d  %s\n", r.ModSecRuleID, r.Status)
+	}
+	return nil
+}
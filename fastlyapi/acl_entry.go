@@ -0,0 +1,43 @@
+package fastlyapi
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/alienth/go-fastly"
+)
+
+type ACLEntryConfig config
+
+type ACLEntryBatchUpdate struct {
+	Entries []ACLEntryUpdate `json:"entries"`
+}
+
+// ACLEntryUpdate is fastly.ACLEntryUpdate along with whether the entry is
+// negated.
+type ACLEntryUpdate struct {
+	Operation fastly.BatchOperation `json:"op,omitempty"`
+	ID        string                `json:"id,omitempty"`
+	IP        string                `json:"ip,omitempty"`
+	Subnet    string                `json:"subnet,omitempty"` // Optional
+	Comment   string                `json:"comment"`
+	Negated   fastly.Compatibool    `json:"negated"`
+}
+
+func (c *ACLEntryConfig) BatchUpdate(serviceID, aclID string, entries []ACLEntryUpdate) (*http.Response, error) {
+	u := fmt.Sprintf("/service/%s/acl/%s/entries", serviceID, aclID)
+
+	var update ACLEntryBatchUpdate
+	update.Entries = entries
+	req, err := c.client.NewJSONRequest("PATCH", u, update)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.Do(req, nil)
+	if err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
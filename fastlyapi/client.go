@@ -0,0 +1,78 @@
+// Package fastlyapi provides the Fastly API endpoints and fields fastlyctl
+// relies on which the vendored go-fastly lacks, built on go-fastly's request
+// handling so that authentication, rate limiting and errors behave the same.
+package fastlyapi
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/alienth/go-fastly"
+)
+
+type config struct {
+	client *fastly.Client
+}
+
+// Client holds the configs for each part of the API, in the same way as
+// fastly.Client.
+type Client struct {
+	common config
+
+	ACLEntry       *ACLEntryConfig
+	Dictionary     *DictionaryConfig
+	DictionaryItem *DictionaryItemConfig
+	Diff           *DiffConfig
+	Director       *DirectorConfig
+	Domain         *DomainConfig
+	GCS            *GCSConfig
+	Purge          *PurgeConfig
+	Service        *ServiceConfig
+	Snippet        *SnippetConfig
+	Stats          *StatsConfig
+	TLS            *TLSConfig
+	Token          *TokenConfig
+	User           *UserConfig
+	VCL            *VCLConfig
+	WAF            *WAFConfig
+}
+
+// New returns a Client which sends its requests through client.
+func New(client *fastly.Client) *Client {
+	c := &Client{common: config{client: client}}
+	c.ACLEntry = (*ACLEntryConfig)(&c.common)
+	c.Dictionary = (*DictionaryConfig)(&c.common)
+	c.DictionaryItem = (*DictionaryItemConfig)(&c.common)
+	c.Diff = (*DiffConfig)(&c.common)
+	c.Director = (*DirectorConfig)(&c.common)
+	c.Domain = (*DomainConfig)(&c.common)
+	c.GCS = (*GCSConfig)(&c.common)
+	c.Purge = (*PurgeConfig)(&c.common)
+	c.Service = (*ServiceConfig)(&c.common)
+	c.Snippet = (*SnippetConfig)(&c.common)
+	c.Stats = (*StatsConfig)(&c.common)
+	c.TLS = (*TLSConfig)(&c.common)
+	c.Token = (*TokenConfig)(&c.common)
+	c.User = (*UserConfig)(&c.common)
+	c.VCL = (*VCLConfig)(&c.common)
+	c.WAF = (*WAFConfig)(&c.common)
+	return c
+}
+
+// hasNextPage reports whether a paginated list has more pages after the one in
+// resp. The Link header is used if present. Otherwise a full page is taken to
+// mean there may be more.
+func hasNextPage(resp *http.Response, count, perPage int) bool {
+	if count == 0 {
+		return false
+	}
+	if links := resp.Header.Get("Link"); links != "" {
+		for _, link := range strings.Split(links, ",") {
+			if strings.Contains(link, `rel="next"`) {
+				return true
+			}
+		}
+		return false
+	}
+	return count >= perPage
+}
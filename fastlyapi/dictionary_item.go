@@ -0,0 +1,79 @@
+package fastlyapi
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/alienth/go-fastly"
+)
+
+type DictionaryItemConfig config
+
+// dictionaryItemsByKey is a sortable list of dictionaryItems.
+type dictionaryItemsByKey []*fastly.DictionaryItem
+
+// Len, Swap, and Less implement the sortable interface.
+func (s dictionaryItemsByKey) Len() int      { return len(s) }
+func (s dictionaryItemsByKey) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s dictionaryItemsByKey) Less(i, j int) bool {
+	return s[i].Key < s[j].Key
+}
+
+// dictionaryItemsPerPage is the page size used by List.
+const dictionaryItemsPerPage = 100
+
+// List all dictionaryItems for a specific Dictionary and service, fetching
+// every page.
+func (c *DictionaryItemConfig) List(serviceID, dictionaryID string) ([]*fastly.DictionaryItem, *http.Response, error) {
+	var items []*fastly.DictionaryItem
+	for page := 1; ; page++ {
+		pageItems, resp, err := c.ListPage(serviceID, dictionaryID, page, dictionaryItemsPerPage)
+		if err != nil {
+			return nil, resp, err
+		}
+		items = append(items, pageItems...)
+		if !hasNextPage(resp, len(pageItems), dictionaryItemsPerPage) {
+			sort.Stable(dictionaryItemsByKey(items))
+			return items, resp, nil
+		}
+	}
+}
+
+// ListPage fetches a single page of dictionaryItems. Pages are numbered from 1.
+func (c *DictionaryItemConfig) ListPage(serviceID, dictionaryID string, page, perPage int) ([]*fastly.DictionaryItem, *http.Response, error) {
+	u := fmt.Sprintf("/service/%s/dictionary/%s/items?page=%d&per_page=%d", serviceID, dictionaryID, page, perPage)
+
+	req, err := c.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dictionaryItems := new([]*fastly.DictionaryItem)
+	resp, err := c.client.Do(req, dictionaryItems)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return *dictionaryItems, resp, nil
+}
+
+// BatchUpdate applies items in a single request. Unlike go-fastly's, it does
+// not print the request body.
+func (c *DictionaryItemConfig) BatchUpdate(serviceID, dictionaryID string, items []fastly.DictionaryItemUpdate) (*http.Response, error) {
+	u := fmt.Sprintf("/service/%s/dictionary/%s/items", serviceID, dictionaryID)
+
+	var update fastly.DictionaryItemBatchUpdate
+	update.Items = items
+	req, err := c.client.NewJSONRequest("PATCH", u, update)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.Do(req, nil)
+	if err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
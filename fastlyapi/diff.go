@@ -0,0 +1,47 @@
+package fastlyapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/alienth/go-fastly"
+)
+
+type DiffConfig config
+
+// DiffFormatJSON is a diff format in addition to those of fastly.DiffFormat.
+const DiffFormatJSON = "json"
+
+// Get fetches the diff between two versions in the given format. The diff of
+// the json format is a JSON document rather than a string, and is returned
+// as its raw JSON text.
+func (c *DiffConfig) Get(serviceID string, from, to uint, format fastly.DiffFormat) (*fastly.Diff, *http.Response, error) {
+	u := fmt.Sprintf("/service/%s/diff/from/%d/to/%d", serviceID, from, to)
+	if format != "" {
+		u += fmt.Sprintf("?format=%s", format)
+	}
+
+	req, err := c.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	raw := new(struct {
+		Diff json.RawMessage `json:"diff"`
+	})
+	resp, err := c.client.Do(req, raw)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	diff := &fastly.Diff{ServiceID: serviceID, FromVersion: from, ToVersion: to, Format: format}
+	if len(raw.Diff) > 0 && raw.Diff[0] == '"' {
+		if err = json.Unmarshal(raw.Diff, &diff.Diff); err != nil {
+			return nil, resp, err
+		}
+	} else {
+		diff.Diff = string(raw.Diff)
+	}
+	return diff, resp, nil
+}
@@ -0,0 +1,187 @@
+package fastlyapi
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+type DirectorType uint
+
+const (
+	DirectorTypeRandom DirectorType = 1
+	DirectorTypeHash   DirectorType = 3
+	DirectorTypeClient DirectorType = 4
+)
+
+// DirectorTypes maps the names of director types to their values.
+var DirectorTypes = map[string]DirectorType{
+	"random": DirectorTypeRandom,
+	"hash":   DirectorTypeHash,
+	"client": DirectorTypeClient,
+}
+
+// String returns the name of the director type.
+func (t DirectorType) String() string {
+	for name, value := range DirectorTypes {
+		if value == t {
+			return name
+		}
+	}
+	return fmt.Sprintf("%d", uint(t))
+}
+
+type DirectorConfig config
+
+type Director struct {
+	ServiceID string `json:"service_id,omitempty"`
+	Version   uint   `json:"version,omitempty"`
+
+	Name     string       `json:"name,omitempty"`
+	Type     DirectorType `json:"type,omitempty"`
+	Quorum   uint         `json:"quorum"`
+	Retries  uint         `json:"retries,omitempty"`
+	Capacity uint         `json:"capacity,omitempty"`
+	Comment  string       `json:"comment,omitempty"`
+	Shield   string       `json:"shield,omitempty"`
+
+	// Backends holds the names of the member backends. It is read-only;
+	// members are managed with AddBackend and RemoveBackend.
+	Backends []string `json:"backends,omitempty"`
+}
+
+// directorsByName is a sortable list of directors.
+type directorsByName []*Director
+
+// Len, Swap, and Less implement the sortable interface.
+func (s directorsByName) Len() int      { return len(s) }
+func (s directorsByName) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s directorsByName) Less(i, j int) bool {
+	return s[i].Name < s[j].Name
+}
+
+// List directors for a specific service and version.
+func (c *DirectorConfig) List(serviceID string, version uint) ([]*Director, *http.Response, error) {
+	u := fmt.Sprintf("/service/%s/version/%d/director", serviceID, version)
+
+	req, err := c.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	directors := new([]*Director)
+	resp, err := c.client.Do(req, directors)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	sort.Stable(directorsByName(*directors))
+
+	return *directors, resp, nil
+}
+
+// Get fetches a specific director by name.
+func (c *DirectorConfig) Get(serviceID string, version uint, name string) (*Director, *http.Response, error) {
+	u := fmt.Sprintf("/service/%s/version/%d/director/%s", serviceID, version, name)
+
+	req, err := c.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	director := new(Director)
+	resp, err := c.client.Do(req, director)
+	if err != nil {
+		return nil, resp, err
+	}
+	return director, resp, nil
+}
+
+// Create a new director. Member backends must be added separately with
+// AddBackend.
+func (c *DirectorConfig) Create(serviceID string, version uint, director *Director) (*Director, *http.Response, error) {
+	u := fmt.Sprintf("/service/%s/version/%d/director", serviceID, version)
+
+	req, err := c.client.NewJSONRequest("POST", u, director)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	d := new(Director)
+	resp, err := c.client.Do(req, d)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return d, resp, nil
+}
+
+// Update a director
+func (c *DirectorConfig) Update(serviceID string, version uint, name string, director *Director) (*Director, *http.Response, error) {
+	u := fmt.Sprintf("/service/%s/version/%d/director/%s", serviceID, version, name)
+
+	req, err := c.client.NewJSONRequest("PUT", u, director)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	d := new(Director)
+	resp, err := c.client.Do(req, d)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return d, resp, nil
+}
+
+// Delete a director
+func (c *DirectorConfig) Delete(serviceID string, version uint, name string) (*http.Response, error) {
+	u := fmt.Sprintf("/service/%s/version/%d/director/%s", serviceID, version, name)
+
+	req, err := c.client.NewRequest("DELETE", u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.Do(req, nil)
+	if err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+// AddBackend adds an existing backend to a director.
+func (c *DirectorConfig) AddBackend(serviceID string, version uint, director, backend string) (*http.Response, error) {
+	u := fmt.Sprintf("/service/%s/version/%d/director/%s/backend/%s", serviceID, version, director, backend)
+
+	req, err := c.client.NewRequest("POST", u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.Do(req, nil)
+	if err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+// RemoveBackend removes a backend from a director. The backend itself is
+// left in place.
+func (c *DirectorConfig) RemoveBackend(serviceID string, version uint, director, backend string) (*http.Response, error) {
+	u := fmt.Sprintf("/service/%s/version/%d/director/%s/backend/%s", serviceID, version, director, backend)
+
+	req, err := c.client.NewRequest("DELETE", u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.Do(req, nil)
+	if err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,74 @@
+package fastlyapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/alienth/go-fastly"
+)
+
+type DomainConfig config
+
+// DomainCheck is the result of checking whether a domain's DNS is pointed at
+// Fastly.
+type DomainCheck struct {
+	Domain *fastly.Domain
+	// CNAME is the record the domain currently points at.
+	CNAME string
+	// Success is true if the domain is correctly pointed at Fastly.
+	Success bool
+}
+
+// The API returns a check as a [domain, cname, success] array.
+func (d *DomainCheck) UnmarshalJSON(b []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	if len(raw) != 3 {
+		return fmt.Errorf("unexpected domain check response: %s", b)
+	}
+	d.Domain = new(fastly.Domain)
+	if err := json.Unmarshal(raw[0], d.Domain); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(raw[1], &d.CNAME); err != nil {
+		return err
+	}
+	return json.Unmarshal(raw[2], &d.Success)
+}
+
+// Check checks the DNS status of a specific domain.
+func (c *DomainConfig) Check(serviceID string, version uint, name string) (*DomainCheck, *http.Response, error) {
+	u := fmt.Sprintf("/service/%s/version/%d/domain/%s/check", serviceID, version, name)
+
+	req, err := c.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	check := new(DomainCheck)
+	resp, err := c.client.Do(req, check)
+	if err != nil {
+		return nil, resp, err
+	}
+	return check, resp, nil
+}
+
+// CheckAll checks the DNS status of every domain on a version.
+func (c *DomainConfig) CheckAll(serviceID string, version uint) ([]*DomainCheck, *http.Response, error) {
+	u := fmt.Sprintf("/service/%s/version/%d/domain/check_all", serviceID, version)
+
+	req, err := c.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	checks := new([]*DomainCheck)
+	resp, err := c.client.Do(req, checks)
+	if err != nil {
+		return nil, resp, err
+	}
+	return *checks, resp, nil
+}
@@ -0,0 +1,77 @@
+package fastlyapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+// RequestIDHeaders are the response headers which may hold the ID Fastly
+// assigns to an API request, in order of preference.
+var RequestIDHeaders = []string{"Fastly-Request-ID", "X-Request-ID"}
+
+// RequestID returns the ID Fastly assigned to the request of resp, or an
+// empty string if there is none. Fastly support asks for this ID when
+// investigating failed API calls.
+func RequestID(resp *http.Response) string {
+	if resp == nil {
+		return ""
+	}
+	for _, header := range RequestIDHeaders {
+		if id := resp.Header.Get(header); id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+// ErrorTransport wraps an http.RoundTripper, adding the request ID of each
+// failed API call to the message in its response body. go-fastly builds its
+// errors from that message, so the ID ends up in every API error.
+type ErrorTransport struct {
+	Base http.RoundTripper
+}
+
+func (t *ErrorTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	resp, err := base.RoundTrip(req)
+	if err != nil || resp.StatusCode < 300 {
+		return resp, err
+	}
+	id := RequestID(resp)
+	if id == "" {
+		return resp, nil
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	// The fields are those of fastly.ErrorResponse. A body which is not
+	// JSON, such as an error page from a proxy, is not shown in errors
+	// anyway, so it is replaced.
+	var message map[string]interface{}
+	if json.Unmarshal(body, &message) != nil || message == nil {
+		message = make(map[string]interface{})
+	}
+	field := "detail"
+	if resp.StatusCode == http.StatusTooManyRequests {
+		// Rate limit errors only show the msg field.
+		field = "msg"
+	}
+	if text, ok := message[field].(string); ok || message[field] == nil {
+		message[field] = text + fmt.Sprintf(" (request ID: %s)", id)
+		if body, err = json.Marshal(message); err != nil {
+			return nil, err
+		}
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	return resp, nil
+}
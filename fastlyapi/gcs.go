@@ -0,0 +1,125 @@
+package fastlyapi
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+type GCSConfig config
+
+type GCS struct {
+	ServiceID string `json:"service_id,omitempty"`
+	Version   uint   `json:"version,string,omitempty"`
+
+	Name              string `json:"name,omitempty"`
+	BucketName        string `json:"bucket_name,omitempty"`
+	User              string `json:"user,omitempty"`
+	SecretKey         string `json:"secret_key,omitempty"`
+	Path              string `json:"path"`
+	Period            uint   `json:"period,string,omitempty"`
+	GzipLevel         uint   `json:"gzip_level,string"`
+	Format            string `json:"format"`
+	ResponseCondition string `json:"response_condition"`
+	TimestampFormat   string `json:"timestamp_format"`
+}
+
+// gcssByName is a sortable list of GCS endpoints.
+type gcssByName []*GCS
+
+// Len, Swap, and Less implement the sortable interface.
+func (s gcssByName) Len() int      { return len(s) }
+func (s gcssByName) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s gcssByName) Less(i, j int) bool {
+	return s[i].Name < s[j].Name
+}
+
+// List GCS endpoints for a specific service and version.
+func (c *GCSConfig) List(serviceID string, version uint) ([]*GCS, *http.Response, error) {
+	u := fmt.Sprintf("/service/%s/version/%d/logging/gcs", serviceID, version)
+
+	req, err := c.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	gcss := new([]*GCS)
+	resp, err := c.client.Do(req, gcss)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	sort.Stable(gcssByName(*gcss))
+
+	return *gcss, resp, nil
+}
+
+// Get fetches a specific gcs by name.
+func (c *GCSConfig) Get(serviceID string, version uint, name string) (*GCS, *http.Response, error) {
+	u := fmt.Sprintf("/service/%s/version/%d/logging/gcs/%s", serviceID, version, name)
+
+	req, err := c.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	gcs := new(GCS)
+	resp, err := c.client.Do(req, gcs)
+	if err != nil {
+		return nil, resp, err
+	}
+	return gcs, resp, nil
+}
+
+// Create a new GCS endpoint.
+func (c *GCSConfig) Create(serviceID string, version uint, gcs *GCS) (*GCS, *http.Response, error) {
+	u := fmt.Sprintf("/service/%s/version/%d/logging/gcs", serviceID, version)
+
+	req, err := c.client.NewJSONRequest("POST", u, gcs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	b := new(GCS)
+	resp, err := c.client.Do(req, b)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return b, resp, nil
+}
+
+// Update a GCS endpoint
+func (c *GCSConfig) Update(serviceID string, version uint, name string, gcs *GCS) (*GCS, *http.Response, error) {
+	u := fmt.Sprintf("/service/%s/version/%d/logging/gcs/%s", serviceID, version, name)
+
+	req, err := c.client.NewJSONRequest("PUT", u, gcs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	b := new(GCS)
+	resp, err := c.client.Do(req, b)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return b, resp, nil
+}
+
+// Delete a GCS endpoint
+func (c *GCSConfig) Delete(serviceID string, version uint, name string) (*http.Response, error) {
+	u := fmt.Sprintf("/service/%s/version/%d/logging/gcs/%s", serviceID, version, name)
+
+	req, err := c.client.NewRequest("DELETE", u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.Do(req, nil)
+	if err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
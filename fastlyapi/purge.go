@@ -0,0 +1,71 @@
+package fastlyapi
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+type PurgeConfig config
+
+type Purge struct {
+	Status string `json:"status"`
+	ID     string `json:"id"`
+}
+
+func (c *PurgeConfig) purge(u string, soft bool) (*Purge, *http.Response, error) {
+	req, err := c.client.NewRequest("POST", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if soft {
+		req.Header.Set("Fastly-Soft-Purge", "1")
+	}
+
+	purge := new(Purge)
+	resp, err := c.client.Do(req, purge)
+	if err != nil {
+		return nil, resp, err
+	}
+	return purge, resp, nil
+}
+
+// URL purges a single URL. The URL may be given with or without a scheme.
+func (c *PurgeConfig) URL(url string, soft bool) (*Purge, *http.Response, error) {
+	url = strings.TrimPrefix(strings.TrimPrefix(url, "http://"), "https://")
+	return c.purge(fmt.Sprintf("/purge/%s", url), soft)
+}
+
+// Key purges all objects tagged with the given surrogate key.
+func (c *PurgeConfig) Key(serviceID, key string, soft bool) (*Purge, *http.Response, error) {
+	return c.purge(fmt.Sprintf("/service/%s/purge/%s", serviceID, key), soft)
+}
+
+// MaxPurgeKeys is the maximum number of surrogate keys which may be purged
+// in a single call to Keys.
+const MaxPurgeKeys = 256
+
+// Keys purges all objects tagged with any of the given surrogate keys. The
+// purge ID for each key is returned.
+func (c *PurgeConfig) Keys(serviceID string, keys []string, soft bool) (map[string]string, *http.Response, error) {
+	req, err := c.client.NewRequest("POST", fmt.Sprintf("/service/%s/purge", serviceID), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Surrogate-Key", strings.Join(keys, " "))
+	if soft {
+		req.Header.Set("Fastly-Soft-Purge", "1")
+	}
+
+	purges := make(map[string]string)
+	resp, err := c.client.Do(req, &purges)
+	if err != nil {
+		return nil, resp, err
+	}
+	return purges, resp, nil
+}
+
+// All purges all cached objects for a service.
+func (c *PurgeConfig) All(serviceID string) (*Purge, *http.Response, error) {
+	return c.purge(fmt.Sprintf("/service/%s/purge_all", serviceID), false)
+}
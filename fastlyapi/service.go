@@ -0,0 +1,62 @@
+package fastlyapi
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/alienth/go-fastly"
+)
+
+type ServiceConfig config
+
+// Service is a fastly.Service along with its type, such as vcl or wasm.
+type Service struct {
+	fastly.Service
+	Type string `json:"type,omitempty"`
+}
+
+// servicesByName is a sortable list of services. Services sharing a name are
+// ordered by ID.
+type servicesByName []*Service
+
+// Len, Swap, and Less implement the sortable interface.
+func (s servicesByName) Len() int      { return len(s) }
+func (s servicesByName) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s servicesByName) Less(i, j int) bool {
+	if s[i].Name == s[j].Name {
+		return s[i].ID < s[j].ID
+	}
+	return s[i].Name < s[j].Name
+}
+
+// servicesPerPage is the number of services requested in each page of List.
+const servicesPerPage = 100
+
+// List services, fetching every page.
+func (c *ServiceConfig) List() ([]*Service, *http.Response, error) {
+	var services []*Service
+	var resp *http.Response
+	for page := 1; ; page++ {
+		u := fmt.Sprintf("/service?page=%d&per_page=%d", page, servicesPerPage)
+
+		req, err := c.client.NewRequest("GET", u, nil)
+		if err != nil {
+			return nil, resp, err
+		}
+
+		pageServices := new([]*Service)
+		resp, err = c.client.Do(req, pageServices)
+		if err != nil {
+			return nil, resp, err
+		}
+		services = append(services, *pageServices...)
+		if len(*pageServices) < servicesPerPage {
+			break
+		}
+	}
+
+	sort.Sort(servicesByName(services))
+
+	return services, resp, nil
+}
@@ -0,0 +1,190 @@
+package fastlyapi
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+type SnippetConfig config
+
+type Snippet struct {
+	ServiceID string `json:"service_id,omitempty"`
+	Version   uint   `json:"version,string,omitempty"`
+
+	ID       string      `json:"id,omitempty"`
+	Name     string      `json:"name,omitempty"`
+	Type     SnippetType `json:"type,omitempty"`
+	Priority uint        `json:"priority,string,omitempty"`
+	Dynamic  uint        `json:"dynamic,string,omitempty"`
+	Content  string      `json:"content,omitempty"`
+}
+
+// SnippetType is the location in the generated VCL a snippet is inserted.
+type SnippetType string
+
+const (
+	SnippetTypeInit    SnippetType = "init"
+	SnippetTypeRecv    SnippetType = "recv"
+	SnippetTypeHash    SnippetType = "hash"
+	SnippetTypeHit     SnippetType = "hit"
+	SnippetTypeMiss    SnippetType = "miss"
+	SnippetTypePass    SnippetType = "pass"
+	SnippetTypeFetch   SnippetType = "fetch"
+	SnippetTypeError   SnippetType = "error"
+	SnippetTypeDeliver SnippetType = "deliver"
+	SnippetTypeLog     SnippetType = "log"
+	SnippetTypeNone    SnippetType = "none"
+)
+
+// SnippetTypes lists all valid snippet types.
+var SnippetTypes = []SnippetType{
+	SnippetTypeInit, SnippetTypeRecv, SnippetTypeHash, SnippetTypeHit,
+	SnippetTypeMiss, SnippetTypePass, SnippetTypeFetch, SnippetTypeError,
+	SnippetTypeDeliver, SnippetTypeLog, SnippetTypeNone,
+}
+
+// DynamicSnippet holds the content of a dynamic snippet, which is not
+// versioned.
+type DynamicSnippet struct {
+	ServiceID string `json:"service_id,omitempty"`
+	ID        string `json:"snippet_id,omitempty"`
+	Content   string `json:"content"`
+}
+
+// snippetsByName is a sortable list of snippets.
+type snippetsByName []*Snippet
+
+// Len, Swap, and Less implement the sortable interface.
+func (s snippetsByName) Len() int      { return len(s) }
+func (s snippetsByName) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s snippetsByName) Less(i, j int) bool {
+	return s[i].Name < s[j].Name
+}
+
+// List snippets for a specific service and version.
+func (c *SnippetConfig) List(serviceID string, version uint) ([]*Snippet, *http.Response, error) {
+	u := fmt.Sprintf("/service/%s/version/%d/snippet", serviceID, version)
+
+	req, err := c.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	snippets := new([]*Snippet)
+	resp, err := c.client.Do(req, snippets)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	sort.Stable(snippetsByName(*snippets))
+
+	return *snippets, resp, nil
+}
+
+// Get fetches a specific snippet by name. The content of dynamic snippets is
+// not included, and must be fetched with GetDynamic.
+func (c *SnippetConfig) Get(serviceID string, version uint, name string) (*Snippet, *http.Response, error) {
+	u := fmt.Sprintf("/service/%s/version/%d/snippet/%s", serviceID, version, name)
+
+	req, err := c.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	snippet := new(Snippet)
+	resp, err := c.client.Do(req, snippet)
+	if err != nil {
+		return nil, resp, err
+	}
+	return snippet, resp, nil
+}
+
+// Create a new snippet.
+func (c *SnippetConfig) Create(serviceID string, version uint, snippet *Snippet) (*Snippet, *http.Response, error) {
+	u := fmt.Sprintf("/service/%s/version/%d/snippet", serviceID, version)
+
+	req, err := c.client.NewJSONRequest("POST", u, snippet)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	b := new(Snippet)
+	resp, err := c.client.Do(req, b)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return b, resp, nil
+}
+
+// Update a snippet
+func (c *SnippetConfig) Update(serviceID string, version uint, name string, snippet *Snippet) (*Snippet, *http.Response, error) {
+	u := fmt.Sprintf("/service/%s/version/%d/snippet/%s", serviceID, version, name)
+
+	req, err := c.client.NewJSONRequest("PUT", u, snippet)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	b := new(Snippet)
+	resp, err := c.client.Do(req, b)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return b, resp, nil
+}
+
+// Delete a snippet
+func (c *SnippetConfig) Delete(serviceID string, version uint, name string) (*http.Response, error) {
+	u := fmt.Sprintf("/service/%s/version/%d/snippet/%s", serviceID, version, name)
+
+	req, err := c.client.NewRequest("DELETE", u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.Do(req, nil)
+	if err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+// GetDynamic fetches the content of a dynamic snippet by ID.
+func (c *SnippetConfig) GetDynamic(serviceID, id string) (*DynamicSnippet, *http.Response, error) {
+	u := fmt.Sprintf("/service/%s/snippet/%s", serviceID, id)
+
+	req, err := c.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	snippet := new(DynamicSnippet)
+	resp, err := c.client.Do(req, snippet)
+	if err != nil {
+		return nil, resp, err
+	}
+	return snippet, resp, nil
+}
+
+// UpdateDynamic replaces the content of a dynamic snippet. The change takes
+// effect immediately, without a new version.
+func (c *SnippetConfig) UpdateDynamic(serviceID, id, content string) (*DynamicSnippet, *http.Response, error) {
+	u := fmt.Sprintf("/service/%s/snippet/%s", serviceID, id)
+
+	req, err := c.client.NewJSONRequest("PUT", u, &DynamicSnippet{Content: content})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	b := new(DynamicSnippet)
+	resp, err := c.client.Do(req, b)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return b, resp, nil
+}
@@ -0,0 +1,106 @@
+package fastlyapi
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+type StatsConfig config
+
+// realtimeURL is the endpoint for the real-time analytics API, which is served
+// separately from the main API.
+const realtimeURL = "https://rt.fastly.com/"
+
+// Stats holds the counters for a single period of a service's analytics.
+type Stats struct {
+	StartTime  uint64 `json:"start_time,omitempty"`
+	Requests   uint64 `json:"requests"`
+	Hits       uint64 `json:"hits"`
+	Miss       uint64 `json:"miss"`
+	Pass       uint64 `json:"pass"`
+	Synth      uint64 `json:"synth"`
+	Errors     uint64 `json:"errors"`
+	Bandwidth  uint64 `json:"bandwidth"`
+	BodySize   uint64 `json:"body_size"`
+	HeaderSize uint64 `json:"header_size"`
+	Status1xx  uint64 `json:"status_1xx"`
+	Status2xx  uint64 `json:"status_2xx"`
+	Status3xx  uint64 `json:"status_3xx"`
+	Status4xx  uint64 `json:"status_4xx"`
+	Status5xx  uint64 `json:"status_5xx"`
+}
+
+type statsResponse struct {
+	Status string   `json:"status"`
+	Msg    string   `json:"msg"`
+	Data   []*Stats `json:"data"`
+}
+
+type realtimeResponse struct {
+	Timestamp uint64 `json:"Timestamp"`
+	Data      []struct {
+		Recorded   uint64 `json:"recorded"`
+		Aggregated *Stats `json:"aggregated"`
+	} `json:"Data"`
+}
+
+// Service fetches historical stats for a service. from and to accept any
+// time format the API does, such as unix timestamps or "1 day ago", and by
+// selects the size of each period ("minute", "hour" or "day"). Empty values
+// use the API defaults.
+func (c *StatsConfig) Service(serviceID, from, to, by string) ([]*Stats, *http.Response, error) {
+	params := url.Values{}
+	if from != "" {
+		params.Set("from", from)
+	}
+	if to != "" {
+		params.Set("to", to)
+	}
+	if by != "" {
+		params.Set("by", by)
+	}
+	u := fmt.Sprintf("/stats/service/%s?%s", serviceID, params.Encode())
+
+	req, err := c.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stats := new(statsResponse)
+	resp, err := c.client.Do(req, stats)
+	if err != nil {
+		return nil, resp, err
+	}
+	if stats.Status != "success" {
+		return nil, resp, fmt.Errorf("Error fetching stats: %s", stats.Msg)
+	}
+	return stats.Data, resp, nil
+}
+
+// Realtime fetches the per-second stats for a service over the last 120
+// seconds.
+func (c *StatsConfig) Realtime(serviceID string) ([]*Stats, *http.Response, error) {
+	u := fmt.Sprintf("%sv1/channel/%s/ts/h", realtimeURL, serviceID)
+
+	req, err := c.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	realtime := new(realtimeResponse)
+	resp, err := c.client.Do(req, realtime)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	var stats []*Stats
+	for _, d := range realtime.Data {
+		if d.Aggregated == nil {
+			continue
+		}
+		d.Aggregated.StartTime = d.Recorded
+		stats = append(stats, d.Aggregated)
+	}
+	return stats, resp, nil
+}
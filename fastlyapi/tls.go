@@ -0,0 +1,200 @@
+package fastlyapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+)
+
+// TLSConfig covers the TLS certificates and subscriptions of an account. These
+// endpoints follow the JSON API format rather than the format of the rest of
+// the API.
+type TLSConfig config
+
+// TLSCertificate is a certificate in use on an account, either uploaded by the
+// customer or issued for a TLSSubscription.
+type TLSCertificate struct {
+	ID           string
+	Name         string
+	Issuer       string
+	IssuedTo     string
+	SerialNumber string
+	NotBefore    string
+	NotAfter     string
+	Domains      []string
+}
+
+// TLSSubscription is a certificate managed by Fastly on behalf of the
+// customer.
+type TLSSubscription struct {
+	ID                   string
+	State                string
+	CertificateAuthority string
+	CreatedAt            string
+	UpdatedAt            string
+	Domains              []string
+	Certificates         []*TLSCertificate
+}
+
+// certificatesByExpiry is a sortable list of certificates.
+type certificatesByExpiry []*TLSCertificate
+
+// Len, Swap, and Less implement the sortable interface.
+func (s certificatesByExpiry) Len() int      { return len(s) }
+func (s certificatesByExpiry) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s certificatesByExpiry) Less(i, j int) bool {
+	return s[i].NotAfter < s[j].NotAfter
+}
+
+type tlsRelationship struct {
+	Data []struct {
+		ID   string `json:"id"`
+		Type string `json:"type"`
+	} `json:"data"`
+}
+
+// ids returns the IDs of the resources in a relationship. For TLS domains the
+// ID is the domain name.
+func (r tlsRelationship) ids() []string {
+	var ids []string
+	for _, d := range r.Data {
+		ids = append(ids, d.ID)
+	}
+	return ids
+}
+
+type tlsResource struct {
+	ID            string                     `json:"id"`
+	Type          string                     `json:"type"`
+	Attributes    json.RawMessage            `json:"attributes"`
+	Relationships map[string]tlsRelationship `json:"relationships"`
+}
+
+type tlsResponse struct {
+	Data     []tlsResource `json:"data"`
+	Included []tlsResource `json:"included"`
+	Links    struct {
+		Next string `json:"next"`
+	} `json:"links"`
+}
+
+type tlsCertificateAttributes struct {
+	Name         string `json:"name"`
+	Issuer       string `json:"issuer"`
+	IssuedTo     string `json:"issued_to"`
+	SerialNumber string `json:"serial_number"`
+	NotBefore    string `json:"not_before"`
+	NotAfter     string `json:"not_after"`
+}
+
+type tlsSubscriptionAttributes struct {
+	State                string `json:"state"`
+	CertificateAuthority string `json:"certificate_authority"`
+	CreatedAt            string `json:"created_at"`
+	UpdatedAt            string `json:"updated_at"`
+}
+
+func (r tlsResource) certificate() (*TLSCertificate, error) {
+	var attrs tlsCertificateAttributes
+	if err := json.Unmarshal(r.Attributes, &attrs); err != nil {
+		return nil, err
+	}
+	return &TLSCertificate{
+		ID:           r.ID,
+		Name:         attrs.Name,
+		Issuer:       attrs.Issuer,
+		IssuedTo:     attrs.IssuedTo,
+		SerialNumber: attrs.SerialNumber,
+		NotBefore:    attrs.NotBefore,
+		NotAfter:     attrs.NotAfter,
+		Domains:      r.Relationships["tls_domains"].ids(),
+	}, nil
+}
+
+// list fetches every page of a TLS collection, passing each page to fn.
+func (c *TLSConfig) list(u string, fn func(*tlsResponse) error) (*http.Response, error) {
+	var resp *http.Response
+	for u != "" {
+		req, err := c.client.NewRequest("GET", u, nil)
+		if err != nil {
+			return resp, err
+		}
+
+		page := new(tlsResponse)
+		if resp, err = c.client.Do(req, page); err != nil {
+			return resp, err
+		}
+		if err = fn(page); err != nil {
+			return resp, err
+		}
+		u = page.Links.Next
+	}
+	return resp, nil
+}
+
+// ListCertificates lists the TLS certificates on the account.
+func (c *TLSConfig) ListCertificates() ([]*TLSCertificate, *http.Response, error) {
+	var certificates []*TLSCertificate
+	resp, err := c.list("/tls/certificates?page[size]=100", func(page *tlsResponse) error {
+		for _, r := range page.Data {
+			cert, err := r.certificate()
+			if err != nil {
+				return err
+			}
+			certificates = append(certificates, cert)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, resp, err
+	}
+
+	sort.Stable(certificatesByExpiry(certificates))
+
+	return certificates, resp, nil
+}
+
+// ListSubscriptions lists the Fastly managed TLS subscriptions on the account,
+// along with the certificates issued for them.
+func (c *TLSConfig) ListSubscriptions() ([]*TLSSubscription, *http.Response, error) {
+	var subscriptions []*TLSSubscription
+	resp, err := c.list("/tls/subscriptions?include=tls_certificates&page[size]=100", func(page *tlsResponse) error {
+		certificates := make(map[string]*TLSCertificate)
+		for _, r := range page.Included {
+			if r.Type != "tls_certificate" {
+				continue
+			}
+			cert, err := r.certificate()
+			if err != nil {
+				return err
+			}
+			certificates[r.ID] = cert
+		}
+
+		for _, r := range page.Data {
+			var attrs tlsSubscriptionAttributes
+			if err := json.Unmarshal(r.Attributes, &attrs); err != nil {
+				return err
+			}
+			subscription := &TLSSubscription{
+				ID:                   r.ID,
+				State:                attrs.State,
+				CertificateAuthority: attrs.CertificateAuthority,
+				CreatedAt:            attrs.CreatedAt,
+				UpdatedAt:            attrs.UpdatedAt,
+				Domains:              r.Relationships["tls_domains"].ids(),
+			}
+			for _, id := range r.Relationships["tls_certificates"].ids() {
+				if cert, ok := certificates[id]; ok {
+					subscription.Certificates = append(subscription.Certificates, cert)
+				}
+			}
+			subscriptions = append(subscriptions, subscription)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, resp, err
+	}
+	return subscriptions, resp, nil
+}
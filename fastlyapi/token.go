@@ -0,0 +1,34 @@
+package fastlyapi
+
+import (
+	"net/http"
+)
+
+type TokenConfig config
+
+type Token struct {
+	ID         string   `json:"id,omitempty"`
+	UserID     string   `json:"user_id,omitempty"`
+	CustomerID string   `json:"customer_id,omitempty"`
+	Name       string   `json:"name,omitempty"`
+	Scope      string   `json:"scope,omitempty"`
+	Services   []string `json:"services,omitempty"`
+	CreatedAt  string   `json:"created_at,omitempty"`
+	LastUsedAt string   `json:"last_used_at,omitempty"`
+	ExpiresAt  string   `json:"expires_at,omitempty"`
+}
+
+// Self fetches the token used to authenticate the request.
+func (c *TokenConfig) Self() (*Token, *http.Response, error) {
+	req, err := c.client.NewRequest("GET", "/tokens/self", nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	token := new(Token)
+	resp, err := c.client.Do(req, token)
+	if err != nil {
+		return nil, resp, err
+	}
+	return token, resp, nil
+}
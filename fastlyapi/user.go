@@ -0,0 +1,30 @@
+package fastlyapi
+
+import (
+	"net/http"
+)
+
+type UserConfig config
+
+type User struct {
+	ID         string `json:"id,omitempty"`
+	Login      string `json:"login,omitempty"`
+	Name       string `json:"name,omitempty"`
+	CustomerID string `json:"customer_id,omitempty"`
+	Role       string `json:"role,omitempty"`
+}
+
+// Current fetches the user who owns the API key in use.
+func (c *UserConfig) Current() (*User, *http.Response, error) {
+	req, err := c.client.NewRequest("GET", "/current_user", nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user := new(User)
+	resp, err := c.client.Do(req, user)
+	if err != nil {
+		return nil, resp, err
+	}
+	return user, resp, nil
+}
@@ -0,0 +1,27 @@
+package fastlyapi
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/alienth/go-fastly"
+)
+
+type VCLConfig config
+
+// Generated fetches the VCL generated by Fastly for a specific version.
+func (c *VCLConfig) Generated(serviceID string, version uint) (*fastly.VCL, *http.Response, error) {
+	u := fmt.Sprintf("/service/%s/version/%d/generated_vcl", serviceID, version)
+
+	req, err := c.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	vcl := new(fastly.VCL)
+	resp, err := c.client.Do(req, vcl)
+	if err != nil {
+		return nil, resp, err
+	}
+	return vcl, resp, nil
+}
@@ -0,0 +1,146 @@
+package fastlyapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+// WAFConfig covers the web application firewalls of a service. Like the TLS
+// endpoints, these follow the JSON API format.
+type WAFConfig config
+
+// WAF is a web application firewall attached to a service version.
+type WAF struct {
+	ID                string
+	Version           uint
+	Disabled          bool
+	PrefetchCondition string
+	Response          string
+	LastPush          string
+	LoggingCount      uint
+	BlockingCount     uint
+	DisabledCount     uint
+}
+
+// WAFRuleStatus is the status of a single rule on a WAF. Status is one of
+// log, block or disabled.
+type WAFRuleStatus struct {
+	ID           string
+	ModSecRuleID uint
+	Status       string
+}
+
+// wafRuleStatusesByRuleID is a sortable list of rule statuses.
+type wafRuleStatusesByRuleID []*WAFRuleStatus
+
+// Len, Swap, and Less implement the sortable interface.
+func (s wafRuleStatusesByRuleID) Len() int      { return len(s) }
+func (s wafRuleStatusesByRuleID) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s wafRuleStatusesByRuleID) Less(i, j int) bool {
+	return s[i].ModSecRuleID < s[j].ModSecRuleID
+}
+
+type wafResource struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	Attributes json.RawMessage `json:"attributes"`
+}
+
+type wafResponse struct {
+	Data  []wafResource `json:"data"`
+	Links struct {
+		Next string `json:"next"`
+	} `json:"links"`
+}
+
+type wafAttributes struct {
+	Version           uint   `json:"version"`
+	Disabled          bool   `json:"disabled"`
+	PrefetchCondition string `json:"prefetch_condition"`
+	Response          string `json:"response"`
+	LastPush          string `json:"last_push"`
+	LoggingCount      uint   `json:"rule_statuses_log_count"`
+	BlockingCount     uint   `json:"rule_statuses_block_count"`
+	DisabledCount     uint   `json:"rule_statuses_disabled_count"`
+}
+
+type wafRuleStatusAttributes struct {
+	ModSecRuleID uint   `json:"modsec_rule_id"`
+	Status       string `json:"status"`
+}
+
+// list fetches every page of a WAF collection, passing each page to fn.
+func (c *WAFConfig) list(u string, fn func(*wafResponse) error) (*http.Response, error) {
+	var resp *http.Response
+	for u != "" {
+		req, err := c.client.NewRequest("GET", u, nil)
+		if err != nil {
+			return resp, err
+		}
+
+		page := new(wafResponse)
+		if resp, err = c.client.Do(req, page); err != nil {
+			return resp, err
+		}
+		if err = fn(page); err != nil {
+			return resp, err
+		}
+		u = page.Links.Next
+	}
+	return resp, nil
+}
+
+// List lists the WAFs on a given service version.
+func (c *WAFConfig) List(serviceID string, version uint) ([]*WAF, *http.Response, error) {
+	u := fmt.Sprintf("/service/%s/version/%d/wafs", serviceID, version)
+	var wafs []*WAF
+	resp, err := c.list(u, func(page *wafResponse) error {
+		for _, r := range page.Data {
+			var attrs wafAttributes
+			if err := json.Unmarshal(r.Attributes, &attrs); err != nil {
+				return err
+			}
+			wafs = append(wafs, &WAF{
+				ID:                r.ID,
+				Version:           attrs.Version,
+				Disabled:          attrs.Disabled,
+				PrefetchCondition: attrs.PrefetchCondition,
+				Response:          attrs.Response,
+				LastPush:          attrs.LastPush,
+				LoggingCount:      attrs.LoggingCount,
+				BlockingCount:     attrs.BlockingCount,
+				DisabledCount:     attrs.DisabledCount,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, resp, err
+	}
+	return wafs, resp, nil
+}
+
+// ListRuleStatuses lists the status of each rule on a given WAF.
+func (c *WAFConfig) ListRuleStatuses(serviceID, wafID string) ([]*WAFRuleStatus, *http.Response, error) {
+	u := fmt.Sprintf("/service/%s/wafs/%s/rule_statuses?page[size]=100", serviceID, wafID)
+	var statuses []*WAFRuleStatus
+	resp, err := c.list(u, func(page *wafResponse) error {
+		for _, r := range page.Data {
+			var attrs wafRuleStatusAttributes
+			if err := json.Unmarshal(r.Attributes, &attrs); err != nil {
+				return err
+			}
+			statuses = append(statuses, &WAFRuleStatus{ID: r.ID, ModSecRuleID: attrs.ModSecRuleID, Status: attrs.Status})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, resp, err
+	}
+
+	sort.Sort(wafRuleStatusesByRuleID(statuses))
+
+	return statuses, resp, nil
+}
@@ -0,0 +1,200 @@
+// Package format renders list output in machine-readable formats.
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	Table = "table"
+	JSON  = "json"
+	YAML  = "yaml"
+
+	// Template renders each item through a user supplied text/template.
+	// It is selected with go-template=TEMPLATE.
+	Template = "go-template"
+)
+
+// Validate returns an error if name is not a known output format.
+func Validate(name string) error {
+	switch name {
+	case Table, JSON, YAML, Template:
+		return nil
+	}
+	return fmt.Errorf("Unknown output format %q. Must be one of: %s, %s, %s, %s=TEMPLATE", name, Table, JSON, YAML, Template)
+}
+
+// ParseTemplate parses the text of a go-template output format.
+func ParseTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("output").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid output template: %s", err)
+	}
+	return tmpl, nil
+}
+
+// WriteTemplate renders each item of v, which must be a slice, through the
+// template text. A newline is written after each item unless the template
+// ends with one.
+func WriteTemplate(w io.Writer, text string, v interface{}) error {
+	tmpl, err := ParseTemplate(text)
+	if err != nil {
+		return err
+	}
+	slice := reflect.ValueOf(v)
+	if slice.Kind() != reflect.Slice {
+		return fmt.Errorf("Template output requires a slice, got %s", slice.Kind())
+	}
+
+	for i := 0; i < slice.Len(); i++ {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, slice.Index(i).Interface()); err != nil {
+			return fmt.Errorf("Error rendering output template: %s", err)
+		}
+		if !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+			buf.WriteByte('\n')
+		}
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Fields returns the names of the exported fields of the struct v, as they
+// are referred to in templates.
+func Fields(v interface{}) []string {
+	t := reflect.Indirect(reflect.ValueOf(v)).Type()
+	var fields []string
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).PkgPath == "" {
+			fields = append(fields, "."+t.Field(i).Name)
+		}
+	}
+	return fields
+}
+
+// Write renders v, which must be a slice of structs, to w in the given
+// format. Tables are command-specific and are not handled here, and templates
+// are rendered with WriteTemplate.
+func Write(w io.Writer, name string, v interface{}) error {
+	switch name {
+	case JSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	case YAML:
+		return writeYAML(w, v)
+	}
+	return fmt.Errorf("Unsupported output format %q", name)
+}
+
+// writeYAML renders a slice as a YAML sequence. Structs are written as
+// mappings keyed by their fields' json tags, in field order, so that the YAML
+// and JSON shapes match. The whole document is encoded before anything is
+// written, so an unsupported value never leaves partial output behind.
+func writeYAML(w io.Writer, v interface{}) error {
+	slice := reflect.ValueOf(v)
+	if slice.Kind() != reflect.Slice {
+		return fmt.Errorf("YAML output requires a slice, got %s", slice.Kind())
+	}
+	if slice.Len() == 0 {
+		_, err := fmt.Fprintln(w, "[]")
+		return err
+	}
+
+	value, err := yamlValue(slice)
+	if err != nil {
+		return err
+	}
+	out, err := yaml.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("Error encoding YAML: %s", err)
+	}
+	_, err = w.Write(out)
+	return err
+}
+
+func fieldKey(field reflect.StructField) string {
+	if tag := strings.Split(field.Tag.Get("json"), ",")[0]; tag != "" && tag != "-" {
+		return tag
+	}
+	return field.Name
+}
+
+// yamlValue converts v into a value the YAML encoder writes in the same shape
+// encoding/json would.
+func yamlValue(v reflect.Value) (interface{}, error) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil, nil
+		}
+		return yamlValue(v.Elem())
+	case reflect.Struct:
+		var m yaml.MapSlice
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if field.PkgPath != "" || field.Tag.Get("json") == "-" {
+				continue
+			}
+			value, err := yamlValue(v.Field(i))
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s", fieldKey(field), err)
+			}
+			m = append(m, yaml.MapItem{Key: fieldKey(field), Value: value})
+		}
+		return m, nil
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil, nil
+		}
+		items := make([]interface{}, v.Len())
+		for i := range items {
+			value, err := yamlValue(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			items[i] = value
+		}
+		return items, nil
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("Unsupported YAML map key type %s", v.Type().Key().Kind())
+		}
+		var keys []string
+		for _, key := range v.MapKeys() {
+			keys = append(keys, key.String())
+		}
+		sort.Strings(keys)
+		var m yaml.MapSlice
+		for _, key := range keys {
+			value, err := yamlValue(v.MapIndex(reflect.ValueOf(key).Convert(v.Type().Key())))
+			if err != nil {
+				return nil, err
+			}
+			m = append(m, yaml.MapItem{Key: key, Value: value})
+		}
+		return m, nil
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return v.Bool(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint(), nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	}
+	return nil, fmt.Errorf("Unsupported YAML value type %s", v.Kind())
+}
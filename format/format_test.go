@@ -0,0 +1,88 @@
+package format
+
+import (
+	"bytes"
+	"testing"
+)
+
+type nested struct {
+	Name string `json:"name"`
+}
+
+type record struct {
+	Name    string            `json:"name"`
+	Enabled bool              `json:"enabled"`
+	Count   uint              `json:"count"`
+	Offset  int               `json:"offset"`
+	Ratio   float64           `json:"ratio"`
+	Tags    []string          `json:"tags"`
+	None    []string          `json:"none"`
+	Child   nested            `json:"child"`
+	Parent  *nested           `json:"parent"`
+	Labels  map[string]string `json:"labels"`
+	Skipped string            `json:"-"`
+}
+
+func TestWriteYAML(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+		want string
+	}{
+		{"empty", []record{}, "[]\n"},
+		{
+			"record",
+			[]record{{
+				Name:    "a: b",
+				Enabled: true,
+				Count:   3,
+				Offset:  -1,
+				Ratio:   0.5,
+				Tags:    []string{"x", "y"},
+				Child:   nested{Name: "c"},
+				Labels:  map[string]string{"z": "1", "a": "2"},
+				Skipped: "hidden",
+			}},
+			`- name: 'a: b'
+  enabled: true
+  count: 3
+  offset: -1
+  ratio: 0.5
+  tags:
+  - x
+  - "y"
+  none: null
+  child:
+    name: c
+  parent: null
+  labels:
+    a: "2"
+    z: "1"
+`,
+		},
+	}
+	for _, test := range tests {
+		var buf bytes.Buffer
+		if err := Write(&buf, YAML, test.v); err != nil {
+			t.Errorf("%s: %s", test.name, err)
+			continue
+		}
+		if buf.String() != test.want {
+			t.Errorf("%s: got\n%s\nwant\n%s", test.name, buf.String(), test.want)
+		}
+	}
+}
+
+func TestWriteYAMLUnsupported(t *testing.T) {
+	type unsupported struct {
+		Name string      `json:"name"`
+		Done chan string `json:"done"`
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, YAML, []unsupported{{Name: "a"}}); err == nil {
+		t.Error("got no error for a channel field")
+	}
+	if buf.Len() > 0 {
+		t.Errorf("partial output written: %q", buf.String())
+	}
+}
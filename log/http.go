@@ -0,0 +1,131 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alienth/fastlyctl/fastlyapi"
+)
+
+// maxBodyLog is the maximum number of bytes of a body which will be logged.
+const maxBodyLog = 4096
+
+// redactedHeaders and redactedFields hold the names of headers and JSON
+// fields whose values are replaced before logging.
+var redactedHeaders = []string{"Fastly-Key", "Authorization"}
+var redactedFields = []string{"access_key", "secret_key", "password", "token", "fastly_key"}
+
+// Transport wraps an http.RoundTripper, logging the method, URL, headers, and
+// body of each request and the status, Fastly request ID, and body of each
+// response when debug logging is enabled. Credentials are redacted.
+type Transport struct {
+	Base http.RoundTripper
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if !debug {
+		return base.RoundTrip(req)
+	}
+
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		if reqBody, err = ioutil.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		req.Body = ioutil.NopCloser(bytes.NewReader(reqBody))
+	}
+	Debug(fmt.Sprintf("http request: method=%s url=%s headers=%s\n", req.Method, req.URL, formatHeaders(req.Header)))
+	if len(reqBody) > 0 {
+		Debug(fmt.Sprintf("http request body: %s\n", RedactBody(reqBody)))
+	}
+
+	start := time.Now()
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		Debug(fmt.Sprintf("http error: method=%s url=%s duration=%s error=%q\n", req.Method, req.URL, time.Since(start), err))
+		return resp, err
+	}
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+	if err != nil {
+		return resp, err
+	}
+	Debug(fmt.Sprintf("http response: method=%s url=%s status=%d duration=%s request_id=%q\n", req.Method, req.URL, resp.StatusCode, time.Since(start), fastlyapi.RequestID(resp)))
+	if len(respBody) > 0 {
+		Debug(fmt.Sprintf("http response body: %s\n", RedactBody(respBody)))
+	}
+	return resp, nil
+}
+
+func formatHeaders(header http.Header) string {
+	var names []string
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var parts []string
+	for _, name := range names {
+		value := strings.Join(header[name], ",")
+		for _, redacted := range redactedHeaders {
+			if http.CanonicalHeaderKey(redacted) == name {
+				value = "REDACTED"
+			}
+		}
+		parts = append(parts, fmt.Sprintf("%s=%q", name, value))
+	}
+	return "{" + strings.Join(parts, " ") + "}"
+}
+
+// RedactBody replaces the values of any credential fields in a JSON body. Non
+// JSON bodies are logged as they are. Long bodies are truncated.
+func RedactBody(body []byte) string {
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err == nil {
+		redactValue(v)
+		if redacted, err := json.Marshal(v); err == nil {
+			body = redacted
+		}
+	}
+	if len(body) > maxBodyLog {
+		return fmt.Sprintf("%s... (%d bytes truncated)", body[:maxBodyLog], len(body)-maxBodyLog)
+	}
+	return string(body)
+}
+
+func redactValue(v interface{}) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			redact := false
+			for _, field := range redactedFields {
+				if strings.EqualFold(key, field) {
+					redact = true
+				}
+			}
+			if redact {
+				v[key] = "REDACTED"
+			} else {
+				redactValue(value)
+			}
+		}
+	case []interface{}:
+		for _, value := range v {
+			redactValue(value)
+		}
+	}
+}
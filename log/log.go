@@ -1,8 +1,12 @@
 package log
 
-import "fmt"
+import (
+	"fmt"
+	"os"
+)
 
 var debug bool
+var quiet bool
 
 func EnableDebug() {
 	debug = true
@@ -12,3 +16,28 @@ func Debug(message string) {
 		fmt.Print(message)
 	}
 }
+
+// EnableQuiet suppresses messages printed with Info.
+func EnableQuiet() {
+	quiet = true
+}
+
+// Quiet reports whether messages printed with Info are suppressed.
+func Quiet() bool {
+	return quiet
+}
+
+// Notice prints a message to stderr, so that it is shown with --quiet and does
+// not mix with output which may be parsed, such as JSON.
+func Notice(message string) {
+	fmt.Fprint(os.Stderr, message)
+}
+
+// Info prints an informational message, such as confirmation that a change
+// was made. Output which is the purpose of a command, warnings and errors
+// should not use Info, so that they are still shown with --quiet.
+func Info(message string) {
+	if !quiet {
+		fmt.Print(message)
+	}
+}
@@ -0,0 +1,71 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/alienth/fastlyctl/log"
+)
+
+var dryRun bool
+
+// SetDryRun enables or disables dry-run mode, in which requests which would
+// change anything in Fastly are printed rather than sent.
+func SetDryRun(enabled bool) {
+	dryRun = enabled
+}
+
+// DryRun reports whether dry-run mode is enabled.
+func DryRun() bool {
+	return dryRun
+}
+
+// DryRunTransport wraps an http.RoundTripper. GET and HEAD requests are sent
+// as normal. Any other request is printed to stderr instead, and answered with a
+// successful response which echoes the request body, so that callers carry on
+// as though the change had been made.
+type DryRunTransport struct {
+	Base http.RoundTripper
+}
+
+func (t *DryRunTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if req.Method == "GET" || req.Method == "HEAD" {
+		return base.RoundTrip(req)
+	}
+
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = ioutil.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+	}
+	log.Notice(fmt.Sprintf("Dry run: would %s %s\n", req.Method, req.URL.Path))
+	if len(bytes.TrimSpace(body)) > 0 {
+		log.Notice(fmt.Sprintf("  %s\n", bytes.TrimSpace([]byte(log.RedactBody(body)))))
+	}
+
+	var object map[string]interface{}
+	if json.Unmarshal(body, &object) != nil {
+		body = []byte("{}")
+	}
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
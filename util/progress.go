@@ -0,0 +1,70 @@
+package util
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/alienth/fastlyctl/log"
+)
+
+// Progress reports how many of a number of items a bulk operation has
+// finished, on stderr. Each finished item is reported on a line of its own,
+// unless the operation prints nothing else and stderr is a terminal, in which
+// case each report replaces the last on a single line. Reports are not shown
+// with --quiet. A Progress may be used by multiple goroutines at once.
+type Progress struct {
+	mu      sync.Mutex
+	total   int
+	done    int
+	inPlace bool
+	drawn   bool
+}
+
+// NewProgress returns a Progress for an operation over total items. exclusive
+// must only be set if nothing else, including prompts, is printed until
+// Finish is called, as other output would run into a line drawn in place.
+func NewProgress(total int, exclusive bool) *Progress {
+	return &Progress{total: total, inPlace: exclusive && IsStderrTerminal()}
+}
+
+// Update reports the status of an item which has not finished. It is only
+// shown when drawing in place.
+func (p *Progress) Update(name, status string) {
+	p.report(name, status, false)
+}
+
+// Done reports the final status of an item, and counts it as finished.
+func (p *Progress) Done(name, status string) {
+	p.report(name, status, true)
+}
+
+func (p *Progress) report(name, status string, finished bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if finished {
+		p.done++
+	}
+	if log.Quiet() {
+		return
+	}
+	line := fmt.Sprintf("[%d/%d] %s: %s", p.done, p.total, name, status)
+	if p.inPlace {
+		// Return to the start of the line and clear it before drawing.
+		fmt.Fprint(os.Stderr, "\r\x1b[K"+line)
+		p.drawn = true
+	} else if finished {
+		fmt.Fprintln(os.Stderr, line)
+	}
+}
+
+// Finish ends the line drawn on a terminal, so that any further output
+// starts on a line of its own.
+func (p *Progress) Finish() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.drawn {
+		fmt.Fprintln(os.Stderr)
+		p.drawn = false
+	}
+}
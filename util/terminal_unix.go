@@ -11,3 +11,11 @@ import (
 func IsInteractive() bool {
 	return terminal.IsTerminal(syscall.Stdin)
 }
+
+func IsStdoutTerminal() bool {
+	return terminal.IsTerminal(syscall.Stdout)
+}
+
+func IsStderrTerminal() bool {
+	return terminal.IsTerminal(syscall.Stderr)
+}
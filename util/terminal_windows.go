@@ -9,3 +9,11 @@ import (
 func IsInteractive() bool {
 	return terminal.IsTerminal(int(syscall.Stdin))
 }
+
+func IsStdoutTerminal() bool {
+	return terminal.IsTerminal(int(syscall.Stdout))
+}
+
+func IsStderrTerminal() bool {
+	return terminal.IsTerminal(int(syscall.Stderr))
+}
@@ -0,0 +1,58 @@
+package util
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+// NewTransport returns a transport for requests to the Fastly API. Additional
+// CAs in the PEM encoded caFile are trusted alongside the system pool, and
+// verification is skipped entirely if insecure is set. Proxies are taken from
+// the HTTPS_PROXY and NO_PROXY environment variables, as with the default
+// transport.
+func NewTransport(caFile string, insecure bool) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: insecure}
+
+	if caFile != "" {
+		pem, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("Error reading CA certificate: %s", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("No PEM certificates found in %s", caFile)
+		}
+		transport.TLSClientConfig.RootCAs = pool
+	}
+	return transport, nil
+}
+
+// APIURLTransport wraps an http.RoundTripper, sending requests for
+// api.fastly.com to the host set with SetAPIURL instead. Clients from
+// fastly.NewClient always use api.fastly.com.
+type APIURLTransport struct {
+	Base http.RoundTripper
+}
+
+func (t *APIURLTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if apiURL == nil || req.URL.Host != "api.fastly.com" {
+		return base.RoundTrip(req)
+	}
+	redirected := req.Clone(req.Context())
+	redirected.URL.Scheme = apiURL.Scheme
+	redirected.URL.Host = apiURL.Host
+	redirected.Host = apiURL.Host
+	return base.RoundTrip(redirected)
+}
@@ -1,35 +1,186 @@
 package util
 
 import (
+	"bufio"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net/http"
 	"net/url"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/BurntSushi/toml"
+	"github.com/alienth/fastlyctl/fastlyapi"
+	"github.com/alienth/fastlyctl/log"
 	"github.com/alienth/go-fastly"
 	"github.com/pmezard/go-difflib/difflib"
 	"github.com/urfave/cli"
+	"gopkg.in/yaml.v2"
 )
 
 var ErrNonInteractive = errors.New("In non-interactive shell and --assume-yes not used.")
 
+var ErrInvalidKey = errors.New("Your Fastly API key is invalid or expired.")
+
+var serviceIDPattern = regexp.MustCompile(`^[0-9A-Za-z]{22}$`)
+
+// IsServiceID returns true if the given string is formatted like a Fastly
+// service ID.
+func IsServiceID(s string) bool {
+	return serviceIDPattern.MatchString(s)
+}
+
+// serviceNotFoundError is returned by GetServiceByName when Fastly reports
+// that no service has the name.
+type serviceNotFoundError struct {
+	err error
+}
+
+func (e *serviceNotFoundError) Error() string {
+	return e.err.Error()
+}
+
+// IsServiceNotFound reports whether err, as returned by GetServiceByName,
+// means that no service has the name. Other errors, such as a failure to
+// reach the API, say nothing about whether the service exists.
+func IsServiceNotFound(err error) bool {
+	_, ok := err.(*serviceNotFoundError)
+	return ok
+}
+
 func GetServiceByName(client *fastly.Client, name string) (*fastly.Service, error) {
+	if service, ok := cachedService(name); ok && service.Name == name {
+		return service, nil
+	}
 	var service *fastly.Service
-	service, _, err := client.Service.Search(name)
+	service, resp, err := client.Service.Search(name)
 	if err != nil {
+		if resp != nil && resp.StatusCode == 404 {
+			return nil, &serviceNotFoundError{err}
+		}
 		return nil, err
 	}
+	// Fastly's search may return a service whose name does not exactly
+	// match. Refuse to guess in that case.
+	if service.Name != name {
+		return nil, ambiguousServiceError(client, name)
+	}
+	// Service names need not be unique, and the search returns only one of
+	// the services sharing a name.
+	services, err := listServices(client)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to check for other services named %s: %s", name, err)
+	}
+	var matches []string
+	for _, s := range services {
+		if s.Name == name {
+			matches = append(matches, fmt.Sprintf("  %s (%s)", s.Name, s.ID))
+		}
+	}
+	if len(matches) > 1 {
+		return nil, fmt.Errorf("%d services are named %s. Specify one of the following by ID:\n%s", len(matches), name, strings.Join(matches, "\n"))
+	}
+	cacheService(service)
 	return service, nil
 }
 
-func GetDictionaryByName(client *fastly.Client, serviceName, dictName string) (*fastly.Dictionary, error) {
+// serviceCache holds services which have already been looked up during this
+// invocation, keyed by both name and ID. Only successful lookups are cached.
+// listing holds every service on the account, fetched once to check lookups
+// by name for duplicates.
+var serviceCache = struct {
+	sync.Mutex
+	services map[string]*fastly.Service
+	listing  []*fastlyapi.Service
+}{services: make(map[string]*fastly.Service)}
+
+// listServices returns every service on the account. The list is fetched on
+// the first call only, so it is only suitable for checking names and IDs.
+func listServices(client *fastly.Client) ([]*fastlyapi.Service, error) {
+	serviceCache.Lock()
+	defer serviceCache.Unlock()
+	if serviceCache.listing == nil {
+		services, _, err := fastlyapi.New(client).Service.List()
+		if err != nil {
+			return nil, err
+		}
+		serviceCache.listing = services
+	}
+	return serviceCache.listing, nil
+}
+
+func cachedService(key string) (*fastly.Service, bool) {
+	serviceCache.Lock()
+	defer serviceCache.Unlock()
+	service, ok := serviceCache.services[key]
+	return service, ok
+}
+
+func cacheService(service *fastly.Service) {
+	serviceCache.Lock()
+	defer serviceCache.Unlock()
+	serviceCache.services[service.Name] = service
+	serviceCache.services[service.ID] = service
+}
+
+// ForgetService removes a service from the lookup cache. It must be called
+// once a service's active version or name has changed, so that later lookups
+// see the change.
+func ForgetService(service *fastly.Service) {
+	serviceCache.Lock()
+	defer serviceCache.Unlock()
+	delete(serviceCache.services, service.Name)
+	delete(serviceCache.services, service.ID)
+}
+
+// ambiguousServiceError returns an error listing services whose names
+// contain the given name, so the user can pick one by ID.
+func ambiguousServiceError(client *fastly.Client, name string) error {
+	services, err := listServices(client)
+	if err != nil {
+		return fmt.Errorf("No service exactly matching %s found", name)
+	}
+	var candidates []string
+	for _, s := range services {
+		if strings.Contains(strings.ToLower(s.Name), strings.ToLower(name)) {
+			candidates = append(candidates, fmt.Sprintf("  %s (%s)", s.Name, s.ID))
+		}
+	}
+	if len(candidates) == 0 {
+		return fmt.Errorf("No service exactly matching %s found", name)
+	}
+	return fmt.Errorf("No service exactly matching %s found. Specify one of the following by ID:\n%s", name, strings.Join(candidates, "\n"))
+}
+
+// GetService resolves the given argument to a service. If the argument looks
+// like a service ID it is first fetched by ID, falling back to a search by
+// name.
+func GetService(client *fastly.Client, arg string) (*fastly.Service, error) {
+	if IsServiceID(arg) {
+		if service, ok := cachedService(arg); ok && service.ID == arg {
+			return service, nil
+		}
+		if service, _, err := client.Service.Get(arg); err == nil {
+			cacheService(service)
+			return service, nil
+		}
+	}
+	return GetServiceByName(client, arg)
+}
+
+func GetDictionaryByName(client *fastly.Client, serviceName, dictName string) (*fastlyapi.Dictionary, error) {
 	var err error
-	service, err := GetServiceByName(client, serviceName)
+	service, err := GetService(client, serviceName)
 	if err != nil {
 		return nil, err
 	}
@@ -39,7 +190,11 @@ func GetDictionaryByName(client *fastly.Client, serviceName, dictName string) (*
 	}
 	_ = activeVersion
 
-	dictionary, _, err := client.Dictionary.Get(service.ID, activeVersion, dictName)
+	var dictionary *fastlyapi.Dictionary
+	err = WithRetry(func() error {
+		dictionary, _, err = fastlyapi.New(client).Dictionary.Get(service.ID, activeVersion, dictName)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -65,21 +220,191 @@ func GetActiveVersion(service *fastly.Service) (uint, error) {
 	return 0, fmt.Errorf("Unable to find the active version for service %s", service.Name)
 }
 
+// SetVersionComment replaces the comment on a version of a service.
+func SetVersionComment(client *fastly.Client, serviceID string, number uint, comment string) error {
+	version, _, err := client.Version.Get(serviceID, number)
+	if err != nil {
+		return fmt.Errorf("Unable to find version %d: %s", number, err)
+	}
+	version.Comment = comment
+	// Zero out unwritable fields
+	version.Updated = ""
+	version.Created = ""
+	if _, _, err := client.Version.Update(serviceID, number, version); err != nil {
+		return fmt.Errorf("Error setting comment on version %d: %s", number, err)
+	}
+	return nil
+}
+
+// waitInterval is how often WaitForActive polls the service.
+const waitInterval = 2 * time.Second
+
+// WaitForActive polls a service until the API reports version as its active
+// version, returning an error if that has not happened within timeout. In
+// dry-run mode nothing was activated, so there is nothing to wait for.
+func WaitForActive(client *fastly.Client, s *fastly.Service, version uint, timeout time.Duration) error {
+	if DryRun() {
+		fmt.Printf("Dry run: not waiting for version %d to become active on service %s.\n", version, s.Name)
+		return nil
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		service, _, err := client.Service.Get(s.ID)
+		if err != nil {
+			log.Debug(fmt.Sprintf("Error polling service %s: %s\n", s.Name, err))
+		} else if active, err := GetActiveVersion(service); err == nil {
+			if active == version {
+				log.Debug(fmt.Sprintf("Version %d is active on service %s\n", version, s.Name))
+				return nil
+			}
+			log.Debug(fmt.Sprintf("Waiting for version %d on service %s, active version is %d\n", version, s.Name, active))
+		}
+		if time.Now().Add(waitInterval).After(deadline) {
+			return fmt.Errorf("Timed out after %s waiting for version %d to become active on service %s", timeout, version, s.Name)
+		}
+		time.Sleep(waitInterval)
+	}
+}
+
+// DraftVersions returns the versions of a service newer than its active
+// version which have been neither activated nor locked. These are usually
+// changes which someone has yet to activate.
+func DraftVersions(service *fastly.Service) []*fastly.Version {
+	activeVersion, _ := GetActiveVersion(service)
+	var drafts []*fastly.Version
+	for _, version := range service.Versions {
+		if version.Number > activeVersion && !version.Active && !version.Locked {
+			drafts = append(drafts, version)
+		}
+	}
+	return drafts
+}
+
+// WarnDrafts prints a warning to stderr listing the draft versions of a
+// service, other than the version given in except.
+func WarnDrafts(service *fastly.Service, except uint) {
+	var drafts []*fastly.Version
+	for _, version := range DraftVersions(service) {
+		if version.Number != except {
+			drafts = append(drafts, version)
+		}
+	}
+	if len(drafts) == 0 {
+		return
+	}
+	activeVersion, _ := GetActiveVersion(service)
+	fmt.Fprintf(os.Stderr, "Warning: service %s has draft versions newer than active version %d which have not been activated:\n", service.Name, activeVersion)
+	for _, version := range drafts {
+		fmt.Fprintf(os.Stderr, "  version %d, last updated %s", version.Number, version.Updated)
+		if version.Comment != "" {
+			fmt.Fprintf(os.Stderr, " (%s)", version.Comment)
+		}
+		fmt.Fprintf(os.Stderr, "\n")
+	}
+	fmt.Fprintf(os.Stderr, "Changes in these drafts will not be included. Use --ignore-drafts to silence this warning.\n")
+}
+
+// promptInput is shared by all prompts, so that input buffered while reading
+// one answer is available to the next.
+var promptInput = bufio.NewReader(os.Stdin)
+
+type promptLine struct {
+	line string
+	err  error
+}
+
+// promptLines receives lines read from promptInput. Input is read in the
+// background so that prompts can time out, while a line typed after a timeout
+// is still delivered to the next prompt.
+var promptLines chan promptLine
+var promptOnce sync.Once
+
+func startPromptReader() {
+	promptLines = make(chan promptLine)
+	go func() {
+		for {
+			line, err := promptInput.ReadString('\n')
+			promptLines <- promptLine{line, err}
+		}
+	}()
+}
+
+var promptTimeout time.Duration
+
+// SetPromptTimeout sets how long prompts which have a default answer wait for
+// input before choosing the default. A zero timeout waits forever.
+func SetPromptTimeout(timeout time.Duration) {
+	promptTimeout = timeout
+}
+
+// Prompt asks a yes or no question, repeating it until a valid answer is
+// given. If input ends before an answer is given, the question is treated as
+// declined.
 func Prompt(question string) (bool, error) {
-	var input string
+	return prompt(question, nil, 0)
+}
+
+// PromptWithDefault asks a yes or no question for which an empty answer
+// selects def. If no answer is given within timeout, def is chosen. A zero
+// timeout waits forever.
+func PromptWithDefault(question string, def bool, timeout time.Duration) (bool, error) {
+	return prompt(question, &def, timeout)
+}
+
+func prompt(question string, def *bool, timeout time.Duration) (bool, error) {
+	promptOnce.Do(startPromptReader)
+	choices := "y/n"
+	if def != nil {
+		if *def {
+			choices = "Y/n"
+		} else {
+			choices = "y/N"
+		}
+	}
+
 	for {
-		fmt.Printf("%s (y/n): ", question)
-		if _, err := fmt.Scanln(&input); err != nil {
-			return false, err
+		fmt.Printf("%s (%s): ", question, choices)
+		var expired <-chan time.Time
+		if def != nil && timeout > 0 {
+			expired = time.After(timeout)
 		}
-		if input == "y" {
+		var answer promptLine
+		select {
+		case answer = <-promptLines:
+		case <-expired:
+			fmt.Printf("\nNo answer after %s, choosing the default of %s.\n", timeout, YesNo(*def))
+			return *def, nil
+		}
+		if answer.err != nil && answer.err != io.EOF {
+			return false, answer.err
+		}
+
+		input := strings.ToLower(strings.TrimSpace(answer.line))
+		switch {
+		case input == "y" || input == "yes":
 			return true, nil
-		} else if input == "n" {
+		case input == "n" || input == "no":
 			return false, nil
-		} else {
-			fmt.Printf("Invalid input: %s", input)
+		case input == "" && def != nil:
+			if answer.err == io.EOF {
+				fmt.Println()
+			}
+			return *def, nil
 		}
+		if answer.err == io.EOF {
+			fmt.Println()
+			return false, nil
+		}
+		fmt.Printf("Invalid input: %s\n", input)
+	}
+}
+
+// YesNo formats b as yes or no.
+func YesNo(b bool) string {
+	if b {
+		return "yes"
 	}
+	return "no"
 }
 
 func CountChanges(diff *string) (int, int) {
@@ -88,91 +413,260 @@ func CountChanges(diff *string) (int, int) {
 	return len(additions.FindAllString(*diff, -1)), len(removals.FindAllString(*diff, -1))
 }
 
+var color = true
+
+// ColorAllowed reports whether output may be colorized, given whether
+// --no-color was passed. Setting NO_COLOR also disables color.
+func ColorAllowed(noColor bool) bool {
+	return !noColor && os.Getenv("NO_COLOR") == ""
+}
+
+// SetColor enables or disables colorized output.
+func SetColor(enabled bool) {
+	color = enabled
+}
+
+func colorEnabled() bool {
+	return color && IsStdoutTerminal()
+}
+
+const (
+	colorRed   = "\x1b[31m"
+	colorGreen = "\x1b[32m"
+	colorCyan  = "\x1b[36m"
+	colorReset = "\x1b[0m"
+)
+
+// colorizeDiff wraps the added and removed lines of a unified diff in ANSI
+// color codes.
+func colorizeDiff(diff string) string {
+	lines := strings.SplitAfter(diff, "\n")
+	for i, line := range lines {
+		var code string
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			continue
+		case strings.HasPrefix(line, "+"):
+			code = colorGreen
+		case strings.HasPrefix(line, "-"):
+			code = colorRed
+		case strings.HasPrefix(line, "@@"):
+			code = colorCyan
+		default:
+			continue
+		}
+		content := strings.TrimSuffix(line, "\n")
+		lines[i] = code + content + colorReset + line[len(content):]
+	}
+	return strings.Join(lines, "")
+}
+
+// ResetDiffOutput truncates the file given with --diff-output, if any, so
+// that diffs may be appended to it with WriteDiffOutput.
+func ResetDiffOutput(c *cli.Context) error {
+	file := c.String("diff-output")
+	if file == "" {
+		return nil
+	}
+	f, err := os.Create(file)
+	if err != nil {
+		return fmt.Errorf("Unable to open diff output file: %s", err)
+	}
+	return f.Close()
+}
+
+// WriteDiffOutput appends diff to the file given with --diff-output, if any.
+func WriteDiffOutput(c *cli.Context, diff string) error {
+	file := c.String("diff-output")
+	if file == "" {
+		return nil
+	}
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("Unable to open diff output file: %s", err)
+	}
+	if _, err = io.WriteString(f, diff); err != nil {
+		f.Close()
+		return fmt.Errorf("Unable to write diff output file: %s", err)
+	}
+	return f.Close()
+}
+
+// PrintDiff prints the diff for the given service. If usePager is set and a
+// pager is available, the diff is sent through the pager.
+func PrintDiff(s *fastly.Service, diff string, usePager bool) {
+	if colorEnabled() {
+		diff = colorizeDiff(diff)
+	}
+	if GetPager() != nil && usePager {
+		Page(diff, true)
+	} else {
+		fmt.Printf("Diff for %s:\n\n", s.Name)
+		fmt.Println(diff)
+	}
+}
+
+// Page prints content through the pager if usePager is set and a pager is
+// available, or directly to stdout otherwise.
+func Page(content string, usePager bool) {
+	pager := GetPager()
+	if pager == nil || !usePager {
+		fmt.Print(content)
+		return
+	}
+
+	r, stdin := io.Pipe()
+	pager.Stdin = r
+	pager.Stdout = os.Stdout
+	pager.Stderr = os.Stderr
+
+	c := make(chan struct{})
+	go func() {
+		defer close(c)
+		pager.Run()
+	}()
+
+	fmt.Fprint(stdin, content)
+	stdin.Close()
+	<-c
+}
+
 func ActivateVersion(c *cli.Context, client *fastly.Client, s *fastly.Service, v *fastly.Version) error {
+	if activeVersion, err := GetActiveVersion(s); err == nil && activeVersion == v.Number {
+		log.Info(fmt.Sprintf("Version %d is already active on service %s. Nothing to do.\n", v.Number, s.Name))
+		return nil
+	}
+	if !c.Bool("ignore-drafts") {
+		WarnDrafts(s, v.Number)
+	}
+	_, err := PromptActivateVersion(c, client, s, v)
+	return err
+}
+
+// PrintChangeSummary prints the number of lines added and removed by the
+// pending changes to a service, in place of the full diff.
+func PrintChangeSummary(s *fastly.Service, additions, removals int) {
+	fmt.Printf("Service %s: %d additions, %d removals\n", s.Name, additions, removals)
+}
+
+// PromptActivateVersion shows the diff for version v and activates it once
+// confirmed. With --summary only the number of changes is shown. It reports
+// whether the version was activated.
+func PromptActivateVersion(c *cli.Context, client *fastly.Client, s *fastly.Service, v *fastly.Version) (bool, error) {
 	activeVersion, err := GetActiveVersion(s)
 	if err != nil {
-		return err
+		return false, err
 	}
 	assumeYes := c.GlobalBool("assume-yes")
 	diff, err := GetUnifiedDiff(client, s, activeVersion, v.Number)
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	interactive := IsInteractive()
 	if !interactive && !assumeYes {
-		return cli.NewExitError(ErrNonInteractive.Error(), -1)
+		return false, cli.NewExitError(ErrNonInteractive.Error(), -1)
+	}
+	if err = WriteDiffOutput(c, diff); err != nil {
+		return false, err
 	}
-	pager := GetPager()
 
-	fmt.Printf("Diff URL: %s\n", GetDiffUrl(s, activeVersion, v.Number).String())
+	log.Info(fmt.Sprintf("Diff URL: %s\n", GetDiffUrl(s, activeVersion, v.Number).String()))
 
 	additions, removals := CountChanges(&diff)
 	var proceed bool
-	if !assumeYes {
-		if proceed, err = Prompt(fmt.Sprintf("%d additions and %d removals in diff. View?", additions, removals)); err != nil {
-			return err
+	if c.Bool("summary") {
+		PrintChangeSummary(s, additions, removals)
+	} else if !assumeYes {
+		if proceed, err = PromptWithDefault(fmt.Sprintf("%d additions and %d removals in diff. View?", additions, removals), false, promptTimeout); err != nil {
+			return false, err
 		}
 	}
 
-	if proceed || assumeYes {
-		if pager != nil && interactive && !assumeYes {
-			r, stdin := io.Pipe()
-			pager.Stdin = r
-			pager.Stdout = os.Stdout
-			pager.Stderr = os.Stderr
-
-			c := make(chan struct{})
-			go func() {
-				defer close(c)
-				pager.Run()
-			}()
-
-			fmt.Fprintf(stdin, diff)
-			stdin.Close()
-			<-c
-		} else {
-			fmt.Printf("Diff for %s:\n\n", s.Name)
-			fmt.Println(diff)
-		}
+	if (proceed || assumeYes) && !c.Bool("summary") {
+		PrintDiff(s, diff, interactive && !assumeYes)
 	}
 
 	if !c.Bool("noop") {
 		if !assumeYes {
-			if proceed, err = Prompt("Activate version " + strconv.Itoa(int(v.Number)) + " for service " + s.Name + "?"); err != nil {
-				return err
+			// Default to not activating, so that an unattended terminal
+			// never activates by accident.
+			if proceed, err = PromptWithDefault("Activate version "+strconv.Itoa(int(v.Number))+" for service "+s.Name+"?", false, promptTimeout); err != nil {
+				return false, err
 			}
 		}
 		if proceed || assumeYes {
-			if _, _, err = client.Version.Activate(s.ID, v.Number); err != nil {
+			err = WithRetry(func() error {
+				_, _, err := client.Version.Activate(s.ID, v.Number)
 				return err
+			})
+			if err != nil {
+				return false, err
+			}
+			ForgetService(s)
+			if DryRun() {
+				fmt.Printf("Dry run: would activate version %d for %s. Old version: %d\n", v.Number, s.Name, activeVersion)
+			} else {
+				log.Info(fmt.Sprintf("Activated version %d for %s. Old version: %d\n", v.Number, s.Name, activeVersion))
+			}
+			if c.Bool("wait") {
+				if err = WaitForActive(client, s, v.Number, c.Duration("wait-timeout")); err != nil {
+					return true, err
+				}
 			}
-			fmt.Printf("Activated version %d for %s. Old version: %d\n", v.Number, s.Name, activeVersion)
+			return true, nil
 		}
 	}
-	return nil
+	return false, nil
 }
 
-// validateVersion takes in a service and version number and returns an
-// error if the version is invalid.
-func ValidateVersion(client *fastly.Client, service *fastly.Service, version uint) error {
+// ValidateVersion takes in a service and version number and returns an
+// error if the version is invalid. Any warnings Fastly reports for a valid
+// version are returned.
+func ValidateVersion(client *fastly.Client, service *fastly.Service, version uint) ([]string, error) {
 	validationResponse, _, err := client.Version.Validate(service.ID, version)
 	if err != nil {
-		return fmt.Errorf("Error validating version: %s", err)
+		return nil, fmt.Errorf("Error validating version: %s", err)
 	}
 
 	prefix := fmt.Sprintf("Version %d on service %s", version, service.Name)
 	if validationResponse.Status == "error" {
-		return fmt.Errorf("%s failed to validate:\n%s\n", prefix, validationResponse.Message)
+		return nil, fmt.Errorf("%s failed to validate:\n%s\n", prefix, validationResponse.Message)
 	} else if len(validationResponse.Warnings) > 0 {
-		fmt.Printf("%s validated with warniings:\n%s\n", prefix, validationResponse.Message)
-		return nil
+		return validationResponse.Warnings, nil
 	} else if validationResponse.Status == "ok" {
-		fmt.Printf("%s successfully validated!\n", prefix)
-		return nil
+		log.Info(fmt.Sprintf("%s successfully validated!\n", prefix))
+		return nil, nil
+	}
+
+	return nil, fmt.Errorf("Unexpected validation response: %+v", validationResponse)
+}
+
+// PrintValidationWarnings prints the warnings returned by ValidateVersion as a
+// numbered list.
+func PrintValidationWarnings(service *fastly.Service, version uint, warnings []string) {
+	fmt.Printf("Version %d on service %s validated with warnings:\n", version, service.Name)
+	for i, warning := range warnings {
+		fmt.Printf("  %d. %s\n", i+1, warning)
 	}
+}
 
-	return fmt.Errorf("Unexpected validation response: %+v", validationResponse)
+// ValidateForActivation validates a version ahead of activating it, printing
+// any warnings. If --fail-on-warnings is set, warnings are treated as a
+// failure unless --force is also given.
+func ValidateForActivation(c *cli.Context, client *fastly.Client, service *fastly.Service, version uint) error {
+	warnings, err := ValidateVersion(client, service, version)
+	if err != nil {
+		return err
+	}
+	if len(warnings) == 0 {
+		return nil
+	}
+	PrintValidationWarnings(service, version, warnings)
+	if c.Bool("fail-on-warnings") && !c.Bool("force") {
+		return fmt.Errorf("Not activating version %d on service %s due to validation warnings. Use --force to activate anyway.", version, service.Name)
+	}
+	return nil
 }
 
 // Returns true if two versions of a given service are identical.  Generated
@@ -183,31 +677,110 @@ func ValidateVersion(client *fastly.Client, service *fastly.Service, version uin
 // and to versions.  If the two diffs are identical, then there is no
 // difference between from and to.
 func VersionsEqual(c *fastly.Client, s *fastly.Service, from, to uint) (bool, error) {
-	noDiff, _, err := c.Diff.Get(s.ID, from, from, "text")
+	noDiff, err := getDiff(c, s, from, from, fastly.DiffFormatText)
 	if err != nil {
 		return false, err
 	}
-	diff, _, err := c.Diff.Get(s.ID, from, to, "text")
+	diff, err := getDiff(c, s, from, to, fastly.DiffFormatText)
 	if err != nil {
 		return false, err
 	}
 	return noDiff.Diff == diff.Diff, nil
 }
 
+// ContainersEqual returns true if two versions of a given service have the
+// same dictionaries and ACLs. Creating or deleting these has no effect on the
+// diff, so VersionsEqual cannot see such changes.
+func ContainersEqual(c *fastly.Client, s *fastly.Service, from, to uint) (bool, error) {
+	names := func(version uint) ([]string, error) {
+		var names []string
+		dictionaries, _, err := fastlyapi.New(c).Dictionary.List(s.ID, version)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range dictionaries {
+			names = append(names, fmt.Sprintf("dictionary %s write_only=%t", d.Name, d.WriteOnly))
+		}
+		acls, _, err := c.ACL.List(s.ID, version)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range acls {
+			names = append(names, "acl "+a.Name)
+		}
+		sort.Strings(names)
+		return names, nil
+	}
+	fromNames, err := names(from)
+	if err != nil {
+		return false, err
+	}
+	toNames, err := names(to)
+	if err != nil {
+		return false, err
+	}
+	return strings.Join(fromNames, "\n") == strings.Join(toNames, "\n"), nil
+}
+
+// getDiff fetches the diff between two versions in the given format, retrying
+// on transient errors.
+func getDiff(c *fastly.Client, s *fastly.Service, from, to uint, format fastly.DiffFormat) (*fastly.Diff, error) {
+	var diff *fastly.Diff
+	err := WithRetry(func() error {
+		var err error
+		diff, _, err = fastlyapi.New(c).Diff.Get(s.ID, from, to, format)
+		return err
+	})
+	return diff, err
+}
+
+// GetFormattedDiff returns the diff between two versions exactly as Fastly
+// renders it in the given format, such as html or json.
+func GetFormattedDiff(c *fastly.Client, s *fastly.Service, from, to uint, format string) (string, error) {
+	diff, err := getDiff(c, s, from, to, fastly.DiffFormat(format))
+	if err != nil {
+		return "", err
+	}
+	return diff.Diff, nil
+}
+
+var diffContext = 3
+
+// SetDiffContext sets the number of context lines used by GetUnifiedDiff and
+// DiffStrings.
+func SetDiffContext(n int) {
+	diffContext = n
+}
+
 func GetUnifiedDiff(c *fastly.Client, s *fastly.Service, from, to uint) (string, error) {
+	return GetUnifiedDiffWithContext(c, s, from, to, diffContext)
+}
+
+// GetUnifiedDiffWithContext returns a unified diff between two versions with
+// the given number of context lines.
+func GetUnifiedDiffWithContext(c *fastly.Client, s *fastly.Service, from, to uint, context int) (string, error) {
 	var fromConfig, toConfig *fastly.Diff
 	var err error
-	if fromConfig, _, err = c.Diff.Get(s.ID, from, from, "text"); err != nil {
+	if fromConfig, err = getDiff(c, s, from, from, fastly.DiffFormatText); err != nil {
 		return "", err
 	}
-	if toConfig, _, err = c.Diff.Get(s.ID, to, to, "text"); err != nil {
+	if toConfig, err = getDiff(c, s, to, to, fastly.DiffFormatText); err != nil {
 		return "", err
 	}
 
+	return diffStrings(fromConfig.Diff, toConfig.Diff, context)
+}
+
+// DiffStrings returns a unified diff between two strings.
+func DiffStrings(from, to string) (string, error) {
+	return diffStrings(from, to, diffContext)
+}
+
+func diffStrings(from, to string, context int) (string, error) {
 	diff := difflib.UnifiedDiff{
-		A:       difflib.SplitLines(fromConfig.Diff),
-		B:       difflib.SplitLines(toConfig.Diff),
-		Context: 3,
+		A:       difflib.SplitLines(from),
+		B:       difflib.SplitLines(to),
+		Context: context,
 	}
 	unified, err := difflib.GetUnifiedDiffString(diff)
 	if err != nil {
@@ -226,36 +799,393 @@ func StringInSlice(check string, slice []string) bool {
 	return false
 }
 
+var pagerEnabled = true
+
+// SetPager enables or disables the use of a pager. When disabled, GetPager
+// finds no pager, so output is always printed directly.
+func SetPager(enabled bool) {
+	pagerEnabled = enabled
+}
+
 func GetPager() *exec.Cmd {
+	if !pagerEnabled {
+		return nil
+	}
 	for _, pager := range [3]string{os.Getenv("PAGER"), "pager", "less"} {
 		// we expect some NotFounds, so ignore errors
 		path, _ := exec.LookPath(pager)
 		if path != "" {
+			if filepath.Base(path) == "less" {
+				// Render color codes rather than escaping them.
+				return exec.Command(path, "-R")
+			}
 			return exec.Command(path)
 		}
 	}
 	return nil
 }
 
+// SetTimeout sets the time limit for each request made to the Fastly API. API
+// clients are created with the default HTTP client, so the limit is applied
+// there. A zero timeout means no limit.
+func SetTimeout(timeout time.Duration) {
+	http.DefaultClient.Timeout = timeout
+}
+
+var maxRetries = 3
+
+// SetMaxRetries sets the number of times WithRetry will retry a failed call.
+func SetMaxRetries(n int) {
+	maxRetries = n
+}
+
+// retryDelay returns how long to wait before retrying after err, and whether
+// err is retryable at all. Rate limit (429) and server (5xx) errors are
+// retryable.
+func retryDelay(err error, attempt int) (time.Duration, bool) {
+	var resp *http.Response
+	switch e := err.(type) {
+	case *fastly.RateLimitError:
+		if wait := e.Rate.Reset.Sub(time.Now()); wait > 0 {
+			return wait, true
+		}
+		resp = e.Response
+	case *fastly.ErrorResponse:
+		if e.Response == nil || e.Response.StatusCode < 500 {
+			return 0, false
+		}
+		resp = e.Response
+	default:
+		return 0, false
+	}
+
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+			return time.Duration(seconds) * time.Second, true
+		}
+	}
+	return time.Second << uint(attempt), true
+}
+
+// WithRetry calls fn, retrying with exponential backoff if it fails due to
+// rate limiting or a server error.
+func WithRetry(fn func() error) error {
+	var err error
+	for attempt := 0; ; attempt++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+		wait, ok := retryDelay(err, attempt)
+		if !ok || attempt >= maxRetries {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "API request failed, retrying in %s: %s\n", wait, err)
+		time.Sleep(wait)
+	}
+}
+
+type Profile struct {
+	FastlyKey string `toml:"fastly_key" json:"fastly_key" yaml:"fastly_key"`
+}
+
+// GetProfileKey reads the Fastly API key for the named profile from the
+// profiles table of the given config file. If path is a directory, each
+// config file within it is searched.
+func GetProfileKey(path, name string) (string, error) {
+	files, err := ConfigFiles(path)
+	if err != nil {
+		return "", err
+	}
+	var profile Profile
+	var found bool
+	var file string
+	for _, file = range files {
+		body, err := ReadConfigFile(file)
+		if err != nil {
+			return "", err
+		}
+		var config struct {
+			Profiles map[string]Profile `toml:"profiles" json:"profiles" yaml:"profiles"`
+		}
+		if ext := ConfigExt(file); ext == ".json" {
+			err = json.Unmarshal(body, &config)
+		} else if ext == ".yaml" || ext == ".yml" {
+			err = yaml.Unmarshal(body, &config)
+		} else {
+			err = toml.Unmarshal(body, &config)
+		}
+		if err != nil {
+			return "", fmt.Errorf("Error parsing profiles in %s: %s", file, err)
+		}
+		if profile, found = config.Profiles[name]; found {
+			break
+		}
+	}
+	if !found {
+		return "", fmt.Errorf("Profile %s not found in %s", name, path)
+	}
+	if profile.FastlyKey == "" {
+		return "", fmt.Errorf("Profile %s in %s has no fastly_key set", name, file)
+	}
+	return profile.FastlyKey, nil
+}
+
+// CheckFastlyKey ensures a Fastly API key has been set. If a profile was
+// selected with --profile, the key is read from that profile and overrides
+// any key given by other means.
 func CheckFastlyKey(c *cli.Context) *cli.ExitError {
+	if profile := c.GlobalString("profile"); profile != "" {
+		file, err := GetConfigFile(c)
+		if err != nil {
+			return cli.NewExitError(fmt.Sprintf("Error: %s", err), -1)
+		}
+		key, err := GetProfileKey(file, profile)
+		if err != nil {
+			return cli.NewExitError(fmt.Sprintf("Error: %s", err), -1)
+		}
+		if err = c.GlobalSet("fastly-key", key); err != nil {
+			return cli.NewExitError(fmt.Sprintf("Error: %s", err), -1)
+		}
+	}
 	if c.GlobalString("fastly-key") == "" {
 		return cli.NewExitError("Error: Fastly API key must be set.", -1)
 	}
 	return nil
 }
 
+// CheckAuth confirms that the Fastly API key is valid, and prints the user and
+// scope it belongs to.
+func CheckAuth(c *cli.Context) *cli.ExitError {
+	who, err := Whoami(c.GlobalString("fastly-key"))
+	if err == ErrInvalidKey {
+		return cli.NewExitError(fmt.Sprintf("Error: %s", err), -1)
+	} else if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	log.Info(fmt.Sprintf("Authenticated as %s.\n", who))
+	return nil
+}
+
+// Whoami describes the user, scope and expiry of a Fastly API key. If the
+// key is rejected by the API, ErrInvalidKey is returned.
+func Whoami(key string) (string, error) {
+	client := fastlyapi.New(fastly.NewClient(nil, key))
+	token, resp, err := client.Token.Self()
+	if err != nil {
+		if resp != nil && (resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden) {
+			return "", ErrInvalidKey
+		}
+		return "", fmt.Errorf("Error checking Fastly API key: %s", err)
+	}
+
+	who := token.UserID
+	// Tokens without global scope cannot read the current user, in which
+	// case the user ID is shown instead.
+	if user, _, err := client.User.Current(); err == nil {
+		who = fmt.Sprintf("%s (%s)", user.Login, user.Name)
+	}
+	expires := "never"
+	if token.ExpiresAt != "" {
+		expires = token.ExpiresAt
+	}
+	return fmt.Sprintf("%s with token %q. Scope: %s. Expires: %s", who, token.Name, token.Scope, expires), nil
+}
+
+// FastlyKeyEnvVars lists the environment variables the Fastly API key is read
+// from, in order of precedence, in the form expected by a flag's EnvVar.
+// FASTLY_API_TOKEN is the name used by Fastly's own tooling.
+const FastlyKeyEnvVars = "FASTLY_KEY,FASTLY_API_TOKEN"
+
+// FastlyKeyFile is the file in CWD the Fastly API key is read from if it is
+// not set in the environment.
+const FastlyKeyFile = "fastly_key"
+
+// GetFastlyKey returns the Fastly API key from the environment, or from the
+// fastly_key file in CWD.
 func GetFastlyKey() string {
-	file := "fastly_key"
-	if _, err := os.Stat(file); err == nil {
-		contents, _ := ioutil.ReadFile(file)
-		if contents[len(contents)-1] == []byte("\n")[0] {
-			contents = contents[:len(contents)-1]
+	for _, env := range strings.Split(FastlyKeyEnvVars, ",") {
+		if key := os.Getenv(env); key != "" {
+			return key
 		}
-		return string(contents)
+	}
+	return readFastlyKeyFile()
+}
+
+func readFastlyKeyFile() string {
+	if _, err := os.Stat(FastlyKeyFile); err == nil {
+		contents, _ := ioutil.ReadFile(FastlyKeyFile)
+		// Strip trailing newlines, including Windows-style CRLF endings.
+		return strings.TrimRight(string(contents), "\r\n")
 	}
 	return ""
 }
 
+// FastlyKeySource describes where the Fastly API key currently in use was
+// read from.
+func FastlyKeySource(c *cli.Context) string {
+	key := c.GlobalString("fastly-key")
+	if profile := c.GlobalString("profile"); profile != "" {
+		return fmt.Sprintf("profile %s", profile)
+	}
+	for _, env := range strings.Split(FastlyKeyEnvVars, ",") {
+		if os.Getenv(env) != "" {
+			// The flag takes precedence over the environment.
+			if os.Getenv(env) == key {
+				return fmt.Sprintf("the %s environment variable", env)
+			}
+			return "the --fastly-key flag"
+		}
+	}
+	if readFastlyKeyFile() == key {
+		return fmt.Sprintf("the %s file", FastlyKeyFile)
+	}
+	return "the --fastly-key flag"
+}
+
+// apiURL holds the scheme and host set with SetAPIURL, if any.
+var apiURL *url.URL
+
+// SetAPIURL points all Fastly API requests at the host in rawURL, rather than
+// api.fastly.com. The requests are redirected by APIURLTransport.
+func SetAPIURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("Invalid API URL %s: %s", rawURL, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("Invalid API URL %s: must be an http or https URL", rawURL)
+	}
+	// API paths are always requested from the root of the host.
+	if (u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "" {
+		return fmt.Errorf("Invalid API URL %s: must not include a path or query", rawURL)
+	}
+	apiURL = &url.URL{Scheme: u.Scheme, Host: u.Host}
+	return nil
+}
+
+// ConfigExtensions lists the file extensions recognized as config files.
+var ConfigExtensions = []string{".toml", ".yaml", ".yml", ".json"}
+
+// IsConfigFile reports whether name has one of the ConfigExtensions.
+func IsConfigFile(name string) bool {
+	return StringInSlice(filepath.Ext(name), ConfigExtensions)
+}
+
+// StdinConfig is the --config value which reads the config from stdin.
+const StdinConfig = "-"
+
+var stdinFormat = "toml"
+var stdinBody []byte
+var stdinRead bool
+
+// SetStdinFormat sets the format of a config read from stdin, which has no
+// extension to infer it from.
+func SetStdinFormat(format string) error {
+	if !StringInSlice("."+format, ConfigExtensions) {
+		return fmt.Errorf("Unknown config format %s. Must be one of: toml, yaml, yml, json", format)
+	}
+	stdinFormat = format
+	return nil
+}
+
+// ConfigExt returns the extension which determines the format of a config
+// file.
+func ConfigExt(file string) string {
+	if file == StdinConfig {
+		return "." + stdinFormat
+	}
+	return filepath.Ext(file)
+}
+
+// ReadConfigFile returns the contents of a config file. Stdin is only read
+// once, so the config may be read again after a profile has been looked up.
+func ReadConfigFile(file string) ([]byte, error) {
+	if file != StdinConfig {
+		return ioutil.ReadFile(file)
+	}
+	if !stdinRead {
+		body, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("Error reading config from stdin: %s", err)
+		}
+		stdinBody = body
+		stdinRead = true
+	}
+	return stdinBody, nil
+}
+
+// ConfigSearchPaths returns the locations searched, in order, for a config
+// file when one has not been explicitly specified.
+func ConfigSearchPaths() []string {
+	var dirs []string
+	if cwd, err := os.Getwd(); err == nil {
+		dirs = append(dirs, cwd)
+	} else {
+		dirs = append(dirs, "")
+	}
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		dirs = append(dirs, filepath.Join(xdg, "fastlyctl"))
+	}
+	if home := os.Getenv("HOME"); home != "" {
+		dirs = append(dirs, filepath.Join(home, ".config", "fastlyctl"))
+	}
+
+	var paths []string
+	for _, dir := range dirs {
+		for _, name := range []string{"config.toml", "config.yaml", "config.yml"} {
+			paths = append(paths, filepath.Join(dir, name))
+		}
+	}
+	return paths
+}
+
+// GetConfigFile returns the config file specified with --config. If --config
+// was not set, the first existing file in ConfigSearchPaths is returned.
+func GetConfigFile(c *cli.Context) (string, error) {
+	if c.GlobalIsSet("config") {
+		return c.GlobalString("config"), nil
+	}
+	paths := ConfigSearchPaths()
+	for _, path := range paths {
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("Unable to find a config file. Searched:\n  %s", strings.Join(paths, "\n  "))
+}
+
+// ConfigFiles returns the config files to be read for path. If path is a
+// directory, all config files within it are returned in sorted order.
+func ConfigFiles(path string) ([]string, error) {
+	if path == StdinConfig {
+		return []string{path}, nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	entries, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !IsConfigFile(name) {
+			continue
+		}
+		files = append(files, filepath.Join(path, name))
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("No config files found in directory %s", path)
+	}
+	return files, nil
+}
+
 func GetDiffUrl(s *fastly.Service, from, to uint) *url.URL {
 	u, _ := url.Parse(fmt.Sprintf("https://manage.fastly.com/configure/services/%s/diff/%d,%d", s.ID, from, to))
 	return u
@@ -0,0 +1,134 @@
+package util
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/alienth/go-fastly"
+)
+
+func TestReadFastlyKeyFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fastlyctl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	if key := readFastlyKeyFile(); key != "" {
+		t.Errorf("with no key file, got %q, want an empty key", key)
+	}
+
+	tests := []struct {
+		name     string
+		contents string
+		want     string
+	}{
+		{"empty", "", ""},
+		{"newline only", "\n", ""},
+		{"CRLF only", "\r\n", ""},
+		{"no newline", "abc123", "abc123"},
+		{"LF", "abc123\n", "abc123"},
+		{"CRLF", "abc123\r\n", "abc123"},
+		{"blank lines", "abc123\r\n\r\n\n", "abc123"},
+	}
+	for _, test := range tests {
+		if err := ioutil.WriteFile(FastlyKeyFile, []byte(test.contents), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if key := readFastlyKeyFile(); key != test.want {
+			t.Errorf("%s: got %q, want %q", test.name, key, test.want)
+		}
+	}
+}
+
+// captureStdout returns what f writes to stdout, including the output of any
+// pager it runs.
+func captureStdout(t *testing.T, f func()) string {
+	out, err := ioutil.TempFile("", "fastlyctl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(out.Name())
+	defer out.Close()
+	stdout := os.Stdout
+	os.Stdout = out
+	defer func() { os.Stdout = stdout }()
+	f()
+	contents, err := ioutil.ReadFile(out.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(contents)
+}
+
+func TestPrintDiffVerbatim(t *testing.T) {
+	diff := "-set req.url = \"/a%20b?id=%s\";\n+set req.url = \"/a%20b?id=%d\";\n"
+	s := &fastly.Service{Name: "example"}
+	defer SetPager(true)
+	defer os.Setenv("PAGER", os.Getenv("PAGER"))
+	os.Setenv("PAGER", "cat")
+
+	for _, usePager := range []bool{false, true} {
+		SetPager(usePager)
+		out := captureStdout(t, func() { PrintDiff(s, diff, usePager) })
+		if !strings.Contains(out, diff) {
+			t.Errorf("with usePager %t, got %q, want it to contain %q", usePager, out, diff)
+		}
+	}
+}
+
+func TestColorizeDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		diff string
+		want string
+	}{
+		{"added", "+a\n", colorGreen + "+a" + colorReset + "\n"},
+		{"removed", "-a\n", colorRed + "-a" + colorReset + "\n"},
+		{"context", " a\n", " a\n"},
+		{"hunk", "@@ -1 +1 @@\n", colorCyan + "@@ -1 +1 @@" + colorReset + "\n"},
+		{"headers", "--- a\n+++ b\n", "--- a\n+++ b\n"},
+		{"no trailing newline", " a\n+b", " a\n" + colorGreen + "+b" + colorReset},
+		{
+			"mixed",
+			"--- a\n+++ b\n@@ -1,2 +1,2 @@\n x\n-y\n+z\n",
+			"--- a\n+++ b\n" + colorCyan + "@@ -1,2 +1,2 @@" + colorReset + "\n x\n" + colorRed + "-y" + colorReset + "\n" + colorGreen + "+z" + colorReset + "\n",
+		},
+	}
+	for _, test := range tests {
+		if got := colorizeDiff(test.diff); got != test.want {
+			t.Errorf("%s: got %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestColorAllowed(t *testing.T) {
+	defer os.Setenv("NO_COLOR", os.Getenv("NO_COLOR"))
+	tests := []struct {
+		name    string
+		noColor bool
+		env     string
+		want    bool
+	}{
+		{"default", false, "", true},
+		{"--no-color", true, "", false},
+		{"NO_COLOR", false, "1", false},
+		{"both", true, "1", false},
+	}
+	for _, test := range tests {
+		os.Setenv("NO_COLOR", test.env)
+		if got := ColorAllowed(test.noColor); got != test.want {
+			t.Errorf("%s: got %t, want %t", test.name, got, test.want)
+		}
+	}
+}